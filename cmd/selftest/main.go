@@ -0,0 +1,153 @@
+// Command selftest runs every bundled example through the sandbox with its
+// scripted input and compares the output with the one it expects, to catch
+// examples broken by a change to the sandbox image or the code validator.
+//
+//	selftest [-examples dir] [-image image] [-run regexp] [-v]
+//
+// Examples without an expected output only have to run without errors.
+// Nothing may be written to standard error. Every example is first checked
+// against the code validator, which needs no sandbox; the accepted ones then
+// run in their own container, removed on exit. selftest exits with status 1
+// if any example fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/examples"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/runner"
+	"github.com/AlexandruC0909/playground/internal/utils"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+func main() {
+	log.SetFlags(0)
+	dir := flag.String("examples", "examples", "examples directory")
+	image := flag.String("image", config.DockerImage, "sandbox image")
+	filter := flag.String("run", "", "only run examples whose name matches this regexp")
+	verbose := flag.Bool("v", false, "print the output of every example")
+	flag.Parse()
+
+	match, err := regexp.Compile(*filter)
+	if err != nil {
+		log.Fatalf("invalid -run: %v", err)
+	}
+
+	catalog, err := examples.Load(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(run(catalog, *image, match, *verbose))
+}
+
+// run tests the examples in catalog whose name matches and returns the exit
+// status.
+func run(catalog *examples.Catalog, image string, match *regexp.Regexp, verbose bool) int {
+	var selected []models.Example
+	var ran, failed int
+	for _, example := range catalog.All() {
+		if !match.MatchString(example.Name) {
+			continue
+		}
+		ran++
+
+		if err := utils.ValidateAndPrepare(example.Code, models.NewSession()); err != nil {
+			failed++
+			fmt.Printf("FAIL %s\n", example.Name)
+			fmt.Println(indent("rejected by the code validator: " + err.Error()))
+			continue
+		}
+		selected = append(selected, example)
+	}
+	if ran == 0 {
+		fmt.Println("no examples to run")
+		return 1
+	}
+
+	container, err := docker.NewContainer(docker.ContainerConfig{
+		Name:        config.SelfTestContainerName,
+		Image:       image,
+		MemoryLimit: config.MemoryLimit,
+		WorkDir:     "/code",
+		Env: []string{
+			"GOMEMLIMIT=50MiB",
+			"GOGC=50",
+			"CGO_ENABLED=0",
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to create Docker container: %v", err)
+		return 1
+	}
+	defer container.Close()
+
+	if err := container.Ensure(); err != nil {
+		log.Printf("Failed to ensure container: %v", err)
+		return 1
+	}
+	defer func() {
+		if err := container.Remove(); err != nil {
+			log.Print(err)
+		}
+	}()
+	executor := docker.NewExecutor(container, "/code")
+
+	for _, example := range selected {
+		result := runner.Run(context.Background(), executor, example.Code, example.Input, models.RunOptions{})
+		problems := check(example, result)
+		if len(problems) > 0 {
+			failed++
+			fmt.Printf("FAIL %s (%v)\n", example.Name, result.Duration.Round(time.Millisecond))
+			for _, problem := range problems {
+				fmt.Println(indent(problem))
+			}
+			continue
+		}
+
+		fmt.Printf("ok   %s (%v)\n", example.Name, result.Duration.Round(time.Millisecond))
+		if verbose {
+			fmt.Println(indent(result.Output))
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("FAIL %d of %d examples\n", failed, ran)
+		return 1
+	}
+	fmt.Printf("ok   %d examples\n", ran)
+	return 0
+}
+
+// check describes everything wrong with the result of running example.
+func check(example models.Example, result models.RunResult) []string {
+	var problems []string
+	if result.Error != "" {
+		problems = append(problems, "error: "+result.Error)
+	}
+	if result.Stderr != "" {
+		problems = append(problems, "stderr:\n"+result.Stderr)
+	}
+	if example.Output != "" && !runner.MatchOutput(result.Output, example.Output) {
+		edits := myers.ComputeEdits(span.URIFromPath("want"), example.Output, result.Output)
+		problems = append(problems, "output differs:\n"+fmt.Sprint(gotextdiff.ToUnified("want", "got", example.Output, edits)))
+	}
+	return problems
+}
+
+func indent(text string) string {
+	text = strings.TrimRight(text, "\n")
+	return "    " + strings.ReplaceAll(text, "\n", "\n    ")
+}
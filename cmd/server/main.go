@@ -7,10 +7,16 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/AlexandruC0909/playground/internal/batch"
+	"github.com/AlexandruC0909/playground/internal/classroom"
 	"github.com/AlexandruC0909/playground/internal/config"
 	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/examples"
 	"github.com/AlexandruC0909/playground/internal/handlers"
+	"github.com/AlexandruC0909/playground/internal/lessons"
+	"github.com/AlexandruC0909/playground/internal/snippets"
 	"github.com/AlexandruC0909/playground/internal/utils"
 	"github.com/docker/docker/client"
 	"github.com/go-chi/chi/v5"
@@ -24,6 +30,7 @@ var (
 	container      *docker.Container
 	executor       *docker.Executor
 	activeSessions = sync.Map{}
+	debugSessions  = sync.Map{}
 )
 
 func init() {
@@ -45,6 +52,14 @@ func fileServer(root http.FileSystem) http.Handler {
 	})
 }
 
+// snippetLocation returns where the configured snippet backend keeps its data.
+func snippetLocation() string {
+	if config.SnippetBackend == snippets.BackendBolt {
+		return config.SnippetDBFile
+	}
+	return config.SnippetDir
+}
+
 func main() {
 	log.Println("Starting Go Playground...")
 	var err error
@@ -53,6 +68,11 @@ func main() {
 		Image:       config.DockerImage,
 		MemoryLimit: config.MemoryLimit,
 		WorkDir:     "/code",
+		Env: []string{
+			"GOMEMLIMIT=50MiB",
+			"GOGC=50",
+			"CGO_ENABLED=0",
+		},
 	}
 
 	container, err = docker.NewContainer(containerConfig)
@@ -66,6 +86,76 @@ func main() {
 	}
 	executor = docker.NewExecutor(container, "/code")
 
+	raceContainer, err := docker.NewContainer(docker.ContainerConfig{
+		Name:        config.RaceContainerName,
+		Image:       config.RaceDockerImage,
+		MemoryLimit: config.RaceMemoryLimit,
+		WorkDir:     "/code",
+		Env: []string{
+			"GOGC=50",
+			"CGO_ENABLED=1",
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create race Docker container: %v", err)
+	}
+	defer raceContainer.Close()
+
+	if err := raceContainer.Ensure(); err != nil {
+		log.Printf("Race detector disabled: %v", err)
+	} else {
+		executor.EnableRace(raceContainer)
+	}
+
+	toolsContainer, err := docker.NewContainer(docker.ContainerConfig{
+		Name:        config.ToolsContainerName,
+		Image:       config.ToolsDockerImage,
+		MemoryLimit: config.ToolsMemoryLimit,
+		WorkDir:     "/code",
+		Env: []string{
+			"GOGC=50",
+			"CGO_ENABLED=0",
+		},
+		CapAdd: []string{"SYS_PTRACE"},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create tools Docker container: %v", err)
+	}
+	defer toolsContainer.Close()
+
+	if err := toolsContainer.Ensure(); err != nil {
+		log.Printf("Debugger and language server disabled: %v", err)
+	} else {
+		executor.EnableDebug(toolsContainer)
+		executor.EnableLanguageServer(toolsContainer)
+	}
+
+	snippetBackend, err := snippets.Open(config.SnippetBackend, snippetLocation())
+	if err != nil {
+		log.Fatalf("Failed to open snippet store: %v", err)
+	}
+	snippetStore := snippets.NewStore(snippetBackend)
+	defer snippetStore.Close()
+	snippetStore.StartReaper(config.SnippetReapIntervalMinutes * time.Minute)
+
+	batchQueue := batch.NewQueue(executor)
+	defer batchQueue.Close()
+
+	rooms := classroom.NewRooms()
+	defer rooms.Close()
+
+	workDir, _ := os.Getwd()
+	catalog, err := examples.Load(filepath.Join(workDir, "../../examples"))
+	if err != nil {
+		log.Printf("Examples disabled: %v", err)
+		catalog = &examples.Catalog{}
+	}
+	courses, err := lessons.Load(filepath.Join(workDir, "../../lessons"))
+	if err != nil {
+		log.Printf("Lessons disabled: %v", err)
+		courses = &lessons.Catalog{}
+	}
+
 	log.Println("Starting HTTP server...")
 
 	r := chi.NewRouter()
@@ -73,14 +163,116 @@ func main() {
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
-	r.Get("/", handlers.HandleHome)
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleHome(w, r, catalog)
+	})
+	r.Get("/api/examples", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleExamples(w, r, catalog)
+	})
+	r.Get("/api/examples/{name}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleExample(w, r, catalog)
+	})
 	r.Post("/run", func(w http.ResponseWriter, r *http.Request) {
-		handlers.HandleRun(w, r, rateLimiter, &activeSessions, executor)
+		handlers.HandleRun(w, r, rateLimiter, &activeSessions, executor, rooms)
 	})
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleHealth(w, r, containerID, localClient)
 	})
-	r.Post("/save", handlers.HandleSave)
+	r.Post("/save", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSave(w, r, executor)
+	})
+	r.Post("/check", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCheck(w, r, rateLimiter)
+	})
+	r.Post("/export", handlers.HandleExport)
+	r.Post("/share", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleShare(w, r, rateLimiter, snippetStore)
+	})
+	r.Get("/p/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSnippet(w, r, snippetStore, catalog)
+	})
+	r.Put("/p/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleUpdateSnippet(w, r, snippetStore)
+	})
+	r.Delete("/p/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeleteSnippet(w, r, snippetStore)
+	})
+	r.Post("/p/{id}/fork", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleFork(w, r, rateLimiter, snippetStore)
+	})
+	r.Get("/p/{id}/export", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSnippetExport(w, r, snippetStore)
+	})
+	r.Get("/embed/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleEmbed(w, r, snippetStore)
+	})
+	r.Get("/metrics/snippets", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSnippetStats(w, r, snippetStore)
+	})
+	r.Get("/p/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSnippetHistory(w, r, snippetStore)
+	})
+	r.Get("/learn", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleLearn(w, r, courses)
+	})
+	r.Get("/learn/{lesson}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleLesson(w, r, courses)
+	})
+	r.Get("/learn/{lesson}/{page}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleLessonPage(w, r, courses)
+	})
+	r.Post("/learn/{lesson}/{page}/check", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleLessonCheck(w, r, rateLimiter, courses, executor)
+	})
+	r.Post("/batch", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleBatch(w, r, rateLimiter, batchQueue)
+	})
+	r.Get("/batch/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleBatchStatus(w, r, batchQueue)
+	})
+	r.Delete("/batch/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCancelBatch(w, r, batchQueue)
+	})
+	r.Get("/batch/{id}/report", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleBatchReport(w, r, batchQueue)
+	})
+	r.Get("/batch/{id}/similarity", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleBatchSimilarity(w, r, batchQueue)
+	})
+	r.Get("/classroom", handlers.HandleClassroom)
+	r.Get("/classroom/{code}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRoomPage(w, r, rooms)
+	})
+	r.Get("/classroom/{code}/dashboard", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRoomDashboard(w, r, rooms)
+	})
+	r.Post("/rooms", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCreateRoom(w, r, rateLimiter, rooms)
+	})
+	r.Get("/rooms/{code}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRoomState(w, r, rooms)
+	})
+	r.Delete("/rooms/{code}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCloseRoom(w, r, rooms)
+	})
+	r.Post("/rooms/{code}/join", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleJoinRoom(w, r, rateLimiter, rooms)
+	})
+	r.Get("/rooms/{code}/events", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRoomEvents(w, r, rooms)
+	})
+	r.Put("/rooms/{code}/code", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRoomCode(w, r, rooms)
+	})
+	r.Put("/rooms/{code}/starter", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRoomStarter(w, r, rooms)
+	})
+	r.Post("/rooms/{code}/broadcast", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRoomBroadcast(w, r, rooms)
+	})
+	r.Get("/rooms/{code}/similarity", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRoomSimilarity(w, r, rooms)
+	})
 	r.Get("/robots.txt", handlers.HandleRobots)
 	r.Get("/program-output", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleProgramOutput(w, r, &activeSessions)
@@ -88,8 +280,31 @@ func main() {
 	r.Post("/send-input", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleSendInput(w, r, &activeSessions)
 	})
+	r.Post("/debug", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDebug(w, r, rateLimiter, &activeSessions, &debugSessions, executor)
+	})
+	r.Post("/debug/command", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDebugCommand(w, r, &debugSessions)
+	})
+	r.Post("/debug/breakpoints", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDebugBreakpoints(w, r, &debugSessions)
+	})
+	r.Delete("/debug/breakpoints", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDebugBreakpoints(w, r, &debugSessions)
+	})
+	r.Get("/debug/goroutines", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDebugGoroutines(w, r, &debugSessions)
+	})
+	r.Get("/debug/stack", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDebugStack(w, r, &debugSessions)
+	})
+	r.Get("/debug/variables", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDebugVariables(w, r, &debugSessions)
+	})
+	r.Get("/lsp", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleLanguageServer(w, r, rateLimiter, executor)
+	})
 
-	workDir, _ := os.Getwd()
 	filesDir := http.Dir(filepath.Join(workDir, "../../static"))
 	r.Handle("/static/*", http.StripPrefix("/static/", fileServer(filesDir)))
 
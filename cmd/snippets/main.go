@@ -0,0 +1,182 @@
+// Command snippets exports, imports and migrates shared snippets between
+// storage backends.
+//
+//	snippets export [-from kind:location] [-o file]
+//	snippets import [-to kind:location] [-i file]
+//	snippets migrate -from kind:location -to kind:location
+//	snippets verify [-from kind:location]
+//
+// Backends are given as "filesystem:dir" or "bolt:file" and default to the
+// server's configuration. Exports are JSON lines, one snippet per line. Every
+// snippet is verified on the way; corrupt ones are reported and skipped.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/snippets"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	from := flags.String("from", defaultSpec(), "source backend")
+	to := flags.String("to", defaultSpec(), "destination backend")
+	output := flags.String("o", "-", "export file")
+	input := flags.String("i", "-", "import file")
+	flags.Parse(os.Args[2:])
+
+	var corrupt int
+	var err error
+	switch os.Args[1] {
+	case "export":
+		corrupt, err = export(*from, *output)
+	case "import":
+		corrupt, err = importSnippets(*input, *to)
+	case "migrate":
+		if *from == *to {
+			log.Fatal("source and destination are the same backend")
+		}
+		corrupt, err = migrate(*from, *to)
+	case "verify":
+		corrupt, err = walk(*from, func(models.Snippet) error { return nil })
+	default:
+		usage()
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+	if corrupt > 0 {
+		log.Fatalf("%d corrupt snippets skipped", corrupt)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: snippets export|import|migrate|verify [flags]")
+	os.Exit(2)
+}
+
+func defaultSpec() string {
+	if config.SnippetBackend == snippets.BackendBolt {
+		return snippets.BackendBolt + ":" + config.SnippetDBFile
+	}
+	return config.SnippetBackend + ":" + config.SnippetDir
+}
+
+// walk calls fn with every intact snippet in the backend given by spec and
+// returns the number of corrupt ones, including records that do not decode.
+func walk(spec string, fn func(models.Snippet) error) (int, error) {
+	backend, err := snippets.OpenSpec(spec)
+	if err != nil {
+		return 0, err
+	}
+	defer backend.Close()
+
+	corrupt := 0
+	err = backend.Walk(func(snippet models.Snippet, err error) error {
+		if err == nil {
+			err = snippets.Verify(snippet)
+		}
+		if err != nil {
+			log.Println(err)
+			corrupt++
+			return nil
+		}
+		return fn(snippet)
+	})
+	return corrupt, err
+}
+
+func export(from string, output string) (int, error) {
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	corrupt, err := walk(from, func(snippet models.Snippet) error {
+		return enc.Encode(snippet)
+	})
+	if err != nil {
+		return corrupt, err
+	}
+	return corrupt, bw.Flush()
+}
+
+func importSnippets(input string, to string) (int, error) {
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	backend, err := snippets.OpenSpec(to)
+	if err != nil {
+		return 0, err
+	}
+	defer backend.Close()
+
+	corrupt, count := 0, 0
+	dec := json.NewDecoder(r)
+	for dec.More() {
+		var snippet models.Snippet
+		if err := dec.Decode(&snippet); err != nil {
+			return corrupt, fmt.Errorf("failed to decode snippet: %v", err)
+		}
+		if err := snippets.Verify(snippet); err != nil {
+			log.Println(err)
+			corrupt++
+			continue
+		}
+		if err := backend.Put(snippet); err != nil {
+			return corrupt, err
+		}
+		count++
+	}
+
+	log.Printf("Imported %d snippets\n", count)
+	return corrupt, nil
+}
+
+func migrate(from string, to string) (int, error) {
+	backend, err := snippets.OpenSpec(to)
+	if err != nil {
+		return 0, err
+	}
+	defer backend.Close()
+
+	count := 0
+	corrupt, err := walk(from, func(snippet models.Snippet) error {
+		count++
+		return backend.Put(snippet)
+	})
+	if err != nil {
+		return corrupt, err
+	}
+
+	log.Printf("Migrated %d snippets\n", count)
+	return corrupt, nil
+}
@@ -0,0 +1,46 @@
+//go:build ignore
+
+// Title: Bubble sort
+// Category: Basics
+// Order: 3
+// Description: Sorts a random slice of integers in place.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+func bubbleSort(arr []int) {
+	n := len(arr)
+	for i := 0; i < n-1; i++ {
+		swapped := false
+		for j := 0; j < n-i-1; j++ {
+			if arr[j] > arr[j+1] {
+				arr[j], arr[j+1] = arr[j+1], arr[j]
+				swapped = true
+			}
+		}
+		if !swapped {
+			break
+		}
+	}
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+
+	arr := make([]int, 30)
+
+	for i := 0; i < 30; i++ {
+		arr[i] = rand.Intn(101)
+	}
+
+	fmt.Println("Unsorted array:", arr)
+
+	bubbleSort(arr)
+
+	fmt.Println("Sorted array:", arr)
+}
@@ -0,0 +1,50 @@
+//go:build ignore
+
+// Title: Fibonacci
+// Category: Basics
+// Order: 2
+// Description: Prints the first Fibonacci numbers with a loop.
+// Output:
+// Fibonacci(0) = 0
+// Fibonacci(1) = 1
+// Fibonacci(2) = 1
+// Fibonacci(3) = 2
+// Fibonacci(4) = 3
+// Fibonacci(5) = 5
+// Fibonacci(6) = 8
+// Fibonacci(7) = 13
+// Fibonacci(8) = 21
+// Fibonacci(9) = 34
+// Fibonacci(10) = 55
+// Fibonacci(11) = 89
+// Fibonacci(12) = 144
+// Fibonacci(13) = 233
+// Fibonacci(14) = 377
+// Fibonacci(15) = 610
+// Fibonacci(16) = 987
+// Fibonacci(17) = 1597
+// Fibonacci(18) = 2584
+// Fibonacci(19) = 4181
+// Fibonacci(20) = 6765
+
+package main
+
+import "fmt"
+
+func fibonacci(n int) {
+	a, b := 0, 1
+	fmt.Printf("Fibonacci(%d) = %d\n", 0, a)
+	if n == 0 {
+		return
+	}
+	fmt.Printf("Fibonacci(%d) = %d\n", 1, b)
+	for i := 2; i <= n; i++ {
+		a, b = b, a+b
+		fmt.Printf("Fibonacci(%d) = %d\n", i, b)
+	}
+}
+
+func main() {
+	n := 20
+	fibonacci(n)
+}
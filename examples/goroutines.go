@@ -0,0 +1,55 @@
+//go:build ignore
+
+// Title: Go routines
+// Category: Basics
+// Order: 4
+// Description: Computes values concurrently and collects them over channels.
+// Output:
+// Square: 9
+// Cube: 27
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+func calculate(n int, calcFunc func(int) int, ch chan int, wg *sync.WaitGroup) {
+	defer wg.Done()
+	time.Sleep(time.Second)
+	ch <- calcFunc(n)
+}
+
+func main() {
+	var wg sync.WaitGroup
+	squareChan := make(chan int)
+	cubeChan := make(chan int)
+
+	number := 3
+
+	wg.Add(2)
+	go calculate(number, func(n int) int { return n * n }, squareChan, &wg)
+	go calculate(number, func(n int) int { return n * n * n }, cubeChan, &wg)
+
+	go func() {
+		wg.Wait()
+		close(squareChan)
+		close(cubeChan)
+	}()
+
+	squareResult, ok := <-squareChan
+	if !ok {
+		fmt.Println("Failed to receive square result")
+	} else {
+		fmt.Printf("Square: %d\n", squareResult)
+	}
+
+	cubeResult, ok := <-cubeChan
+	if !ok {
+		fmt.Println("Failed to receive cube result")
+	} else {
+		fmt.Printf("Cube: %d\n", cubeResult)
+	}
+}
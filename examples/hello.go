@@ -0,0 +1,16 @@
+//go:build ignore
+
+// Title: Hello World!
+// Category: Basics
+// Order: 1
+// Description: Prints a greeting.
+// Output:
+// Hello, World!
+
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("Hello, World!")
+}
@@ -0,0 +1,53 @@
+//go:build ignore
+
+// Title: Matrix multiplication
+// Category: Basics
+// Order: 5
+// Description: Multiplies two matrices stored as slices of slices.
+// Output:
+// Result of matrix multiplication:
+// [19 22]
+// [43 50]
+
+package main
+
+import (
+	"fmt"
+)
+
+func multiplyMatrices(a, b [][]int) [][]int {
+	rowsA, colsA := len(a), len(a[0])
+	_, colsB := len(b), len(b[0])
+
+	result := make([][]int, rowsA)
+	for i := range result {
+		result[i] = make([]int, colsB)
+	}
+
+	for i := 0; i < rowsA; i++ {
+		for j := 0; j < colsB; j++ {
+			for k := 0; k < colsA; k++ {
+				result[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+
+	return result
+}
+
+func main() {
+	a := [][]int{
+		{1, 2},
+		{3, 4},
+	}
+	b := [][]int{
+		{5, 6},
+		{7, 8},
+	}
+
+	result := multiplyMatrices(a, b)
+	fmt.Println("Result of matrix multiplication:")
+	for _, row := range result {
+		fmt.Println(row)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build ignore
+
+// Title: Name and color
+// Category: Interactive
+// Order: 6
+// Description: Reads answers from standard input.
+// Input:
+// Gopher
+// blue
+// Output:
+// What's your name?
+// What's your favorite color?
+// Nice to meet you, Gopher! blue is a great color!
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	scanner := bufio.NewScanner(os.Stdin)
+
+	fmt.Println("What's your name?")
+	scanner.Scan()
+	name := scanner.Text()
+
+	fmt.Println("What's your favorite color?")
+	scanner.Scan()
+	color := scanner.Text()
+
+	fmt.Printf("Nice to meet you, %s! %s is a great color!\n",
+		strings.TrimSpace(name),
+		strings.TrimSpace(color))
+}
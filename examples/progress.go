@@ -0,0 +1,25 @@
+//go:build ignore
+
+// Title: Progress bar
+// Category: Interactive
+// Order: 8
+// Description: Redraws a progress bar by clearing the console.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func main() {
+	const col = 30
+	// Clear the screen by printing \x0c.
+	bar := fmt.Sprintf("\x0c[%%-%vs]", col)
+	for i := 0; i < col; i++ {
+		fmt.Printf(bar, strings.Repeat("=", i)+">")
+		time.Sleep(100 * time.Millisecond)
+	}
+	fmt.Printf(bar+" Done!", strings.Repeat("=", col))
+}
@@ -0,0 +1,338 @@
+package batch
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/runner"
+)
+
+var (
+	// ErrNotFound is returned for jobs that do not exist or were forgotten.
+	ErrNotFound = errors.New("batch job not found")
+	// ErrBusy is returned when config.MaxBatchJobs jobs are unfinished or
+	// the queue is full.
+	ErrBusy = errors.New("too many batch jobs, try again later")
+)
+
+// Queue runs batch jobs in the background. The programs of all jobs are run
+// in the order they were submitted, config.BatchConcurrency at a time.
+type Queue struct {
+	// runProgram runs code, feeding it input.
+	runProgram func(ctx context.Context, code, input string) models.RunResult
+	tasks      chan task
+	done       chan struct{}
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// job is a submitted job. Its status is guarded by mu.
+type job struct {
+	mu          sync.Mutex
+	status      models.BatchJob
+	programs    []models.BatchProgram
+	starterCode string
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// task is a program of a job waiting to run.
+type task struct {
+	job   *job
+	index int
+}
+
+// NewQueue returns a queue running programs with executor, and starts its
+// workers. Finished jobs are forgotten after config.BatchJobTTLMinutes.
+func NewQueue(executor *docker.Executor) *Queue {
+	return newQueue(func(ctx context.Context, code, input string) models.RunResult {
+		return runner.Run(ctx, executor, code, input, models.RunOptions{})
+	})
+}
+
+func newQueue(runProgram func(ctx context.Context, code, input string) models.RunResult) *Queue {
+	q := &Queue{
+		runProgram: runProgram,
+		tasks:      make(chan task, config.MaxBatchJobs*config.MaxBatchPrograms),
+		done:       make(chan struct{}),
+		jobs:       make(map[string]*job),
+	}
+	for i := 0; i < config.BatchConcurrency; i++ {
+		go q.work()
+	}
+	go q.reap()
+	return q
+}
+
+// Submit validates the programs of request and queues them as a new job.
+func (q *Queue) Submit(request models.BatchRequest) (models.BatchJob, error) {
+	programs := request.Programs
+	if len(programs) == 0 {
+		return models.BatchJob{}, fmt.Errorf("no programs submitted")
+	}
+	if len(programs) > config.MaxBatchPrograms {
+		return models.BatchJob{}, fmt.Errorf("a batch may hold at most %d programs", config.MaxBatchPrograms)
+	}
+	if len(request.StarterCode) > config.MaxCodeSize {
+		return models.BatchJob{}, fmt.Errorf("starter code exceeds %d bytes", config.MaxCodeSize)
+	}
+
+	results := make([]models.BatchResult, len(programs))
+	names := make(map[string]bool)
+	for i, program := range programs {
+		if program.Name == "" {
+			program.Name = strconv.Itoa(i + 1)
+		}
+		if names[program.Name] {
+			return models.BatchJob{}, fmt.Errorf("duplicate program name %q", program.Name)
+		}
+		names[program.Name] = true
+		if len(program.Code) > config.MaxCodeSize {
+			return models.BatchJob{}, fmt.Errorf("program %q exceeds %d bytes", program.Name, config.MaxCodeSize)
+		}
+		programs[i] = program
+		results[i] = models.BatchResult{Name: program.Name, Status: models.ProgramPending}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return models.BatchJob{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		status: models.BatchJob{
+			ID:        id,
+			Status:    models.BatchQueued,
+			CreatedAt: time.Now(),
+			Total:     len(programs),
+			Results:   results,
+		},
+		programs:    programs,
+		starterCode: request.StarterCode,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	q.mu.Lock()
+	unfinished := 0
+	for _, other := range q.jobs {
+		if !other.finished() {
+			unfinished++
+		}
+	}
+	// Tasks of cancelled jobs stay queued until a worker drops them, so
+	// the queue itself may be full.
+	if unfinished >= config.MaxBatchJobs || len(q.tasks)+len(programs) > cap(q.tasks) {
+		q.mu.Unlock()
+		cancel()
+		return models.BatchJob{}, ErrBusy
+	}
+	q.jobs[id] = j
+	for i := range programs {
+		q.tasks <- task{job: j, index: i}
+	}
+	q.mu.Unlock()
+
+	return j.snapshot(false), nil
+}
+
+// Job returns the status of the job with the given ID, with the results of
+// its programs if withResults is set.
+func (q *Queue) Job(id string, withResults bool) (models.BatchJob, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	q.mu.Unlock()
+	if !ok {
+		return models.BatchJob{}, ErrNotFound
+	}
+	return j.snapshot(withResults), nil
+}
+
+// Programs returns the programs of the job with the given ID and the starter
+// code they were submitted with.
+func (q *Queue) Programs(id string) ([]models.BatchProgram, string, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	q.mu.Unlock()
+	if !ok {
+		return nil, "", ErrNotFound
+	}
+	// Programs are not changed once submitted.
+	return j.programs, j.starterCode, nil
+}
+
+// Cancel stops the job with the given ID. Programs that have not finished,
+// including those running, are skipped.
+func (q *Queue) Cancel(id string) (models.BatchJob, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	q.mu.Unlock()
+	if !ok {
+		return models.BatchJob{}, ErrNotFound
+	}
+
+	j.mu.Lock()
+	if j.status.Status == models.BatchQueued || j.status.Status == models.BatchRunning {
+		j.status.Status = models.BatchCancelled
+		j.cancel()
+		for i := range j.status.Results {
+			if j.status.Results[i].Status == models.ProgramPending {
+				j.status.Results[i].Status = models.ProgramSkipped
+			}
+		}
+		j.finish()
+	}
+	j.mu.Unlock()
+
+	return j.snapshot(false), nil
+}
+
+// Close stops the workers and cancels every unfinished job.
+func (q *Queue) Close() {
+	close(q.done)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		j.cancel()
+	}
+}
+
+func (q *Queue) work() {
+	for {
+		select {
+		case <-q.done:
+			return
+		case t := <-q.tasks:
+			q.run(t)
+		}
+	}
+}
+
+// run runs a program of a job, unless the job was cancelled, and records
+// its result.
+func (q *Queue) run(t task) {
+	j := t.job
+	j.mu.Lock()
+	if j.status.Status == models.BatchCancelled {
+		j.mu.Unlock()
+		return
+	}
+	j.status.Status = models.BatchRunning
+	j.mu.Unlock()
+
+	program := j.programs[t.index]
+	result := q.runProgram(j.ctx, program.Code, program.Input)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status.Status == models.BatchCancelled {
+		return
+	}
+
+	passed := result.Error == "" && (program.Output == "" || runner.MatchOutput(result.Output, program.Output))
+	j.status.Results[t.index] = models.BatchResult{
+		Name:       program.Name,
+		Status:     models.ProgramFailed,
+		Output:     truncate(result.Output),
+		Stderr:     truncate(result.Stderr),
+		Error:      truncate(result.Error),
+		DurationMs: result.Duration.Milliseconds(),
+	}
+	j.status.Completed++
+	if passed {
+		j.status.Results[t.index].Status = models.ProgramPassed
+		j.status.Passed++
+	} else {
+		j.status.Failed++
+	}
+
+	if j.status.Completed == j.status.Total {
+		j.status.Status = models.BatchDone
+		j.finish()
+	}
+}
+
+// reap forgets finished jobs once they are older than
+// config.BatchJobTTLMinutes, until the queue is closed.
+func (q *Queue) reap() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-ticker.C:
+		}
+		q.forget(time.Now().Add(-config.BatchJobTTLMinutes * time.Minute))
+	}
+}
+
+// forget drops the jobs that finished before cutoff.
+func (q *Queue) forget(cutoff time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, j := range q.jobs {
+		j.mu.Lock()
+		expired := j.status.FinishedAt != nil && j.status.FinishedAt.Before(cutoff)
+		j.mu.Unlock()
+		if expired {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// finish marks the job as finished. j.mu must be held.
+func (j *job) finish() {
+	now := time.Now()
+	j.status.FinishedAt = &now
+	j.cancel()
+	log.Printf("Batch job %s %s: %d passed, %d failed of %d\n", j.status.ID, j.status.Status, j.status.Passed, j.status.Failed, j.status.Total)
+}
+
+func (j *job) finished() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status.FinishedAt != nil
+}
+
+func (j *job) snapshot(withResults bool) models.BatchJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := j.status
+	status.Results = nil
+	if withResults {
+		status.Results = append([]models.BatchResult(nil), j.status.Results...)
+	}
+	return status
+}
+
+func truncate(s string) string {
+	if len(s) > config.MaxBatchOutputSize {
+		return s[:config.MaxBatchOutputSize]
+	}
+	return s
+}
+
+// newID returns a random, unguessable job ID.
+func newID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
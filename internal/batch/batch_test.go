@@ -0,0 +1,317 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// echo prints its code, or fails if the code is "fail".
+func echo(ctx context.Context, code, input string) models.RunResult {
+	if code == "fail" {
+		return models.RunResult{Error: "exit status 1", Duration: time.Millisecond}
+	}
+	return models.RunResult{Output: code + input, Duration: time.Millisecond}
+}
+
+// blocker runs programs until released or cancelled, counting how many run
+// at once.
+type blocker struct {
+	mu      sync.Mutex
+	running int
+	max     int
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlocker() *blocker {
+	return &blocker{started: make(chan struct{}, 1000), release: make(chan struct{})}
+}
+
+func (b *blocker) run(ctx context.Context, code, input string) models.RunResult {
+	b.mu.Lock()
+	b.running++
+	b.max = max(b.max, b.running)
+	b.mu.Unlock()
+	b.started <- struct{}{}
+
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+	}
+
+	b.mu.Lock()
+	b.running--
+	b.mu.Unlock()
+	return models.RunResult{}
+}
+
+// waitStarted waits for n programs to start.
+func (b *blocker) waitStarted(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-b.started:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%d of %d programs started", i, n)
+		}
+	}
+}
+
+// waitStatus waits for the job id to reach status and returns it with its
+// results.
+func waitStatus(t *testing.T, q *Queue, id string, status string) models.BatchJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := q.Job(id, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want %s", id, job.Status, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func programs(codes ...string) []models.BatchProgram {
+	var programs []models.BatchProgram
+	for _, code := range codes {
+		programs = append(programs, models.BatchProgram{Code: code})
+	}
+	return programs
+}
+
+func TestSubmitValidation(t *testing.T) {
+	q := newQueue(echo)
+	defer q.Close()
+
+	large := strings.Repeat("x", config.MaxCodeSize+1)
+	tests := []struct {
+		name    string
+		request models.BatchRequest
+		wantErr string
+	}{
+		{"no programs", models.BatchRequest{}, "no programs"},
+		{"too many", models.BatchRequest{Programs: make([]models.BatchProgram, config.MaxBatchPrograms+1)}, "at most"},
+		{"large starter code", models.BatchRequest{Programs: programs("a"), StarterCode: large}, "starter code"},
+		{"large program", models.BatchRequest{Programs: programs("a", large)}, `program "2" exceeds`},
+		{"duplicate name", models.BatchRequest{Programs: []models.BatchProgram{{Name: "2"}, {}}}, `duplicate program name "2"`},
+	}
+	for _, test := range tests {
+		if _, err := q.Submit(test.request); err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: Submit = %v, want an error containing %q", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestQueueRunsPrograms(t *testing.T) {
+	q := newQueue(echo)
+	defer q.Close()
+
+	job, err := q.Submit(models.BatchRequest{Programs: []models.BatchProgram{
+		{Name: "greeting", Code: "hello", Output: "hello\n"},
+		{Code: "hi", Output: "bye"},
+		{Code: "fail"},
+		{Code: "a", Input: "b"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.BatchQueued || job.Total != 4 || job.Results != nil {
+		t.Errorf("submitted job = %+v, want 4 queued programs without results", job)
+	}
+
+	job = waitStatus(t, q, job.ID, models.BatchDone)
+	if job.Completed != 4 || job.Passed != 2 || job.Failed != 2 || job.FinishedAt == nil {
+		t.Errorf("job = %+v, want 2 passed and 2 failed", job)
+	}
+	want := []models.BatchResult{
+		{Name: "greeting", Status: models.ProgramPassed, Output: "hello", DurationMs: 1},
+		{Name: "2", Status: models.ProgramFailed, Output: "hi", DurationMs: 1},
+		{Name: "3", Status: models.ProgramFailed, Error: "exit status 1", DurationMs: 1},
+		{Name: "4", Status: models.ProgramPassed, Output: "ab", DurationMs: 1},
+	}
+	for i, result := range job.Results {
+		if result != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, result, want[i])
+		}
+	}
+}
+
+func TestQueueConcurrency(t *testing.T) {
+	b := newBlocker()
+	q := newQueue(b.run)
+	defer q.Close()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		job, err := q.Submit(models.BatchRequest{Programs: make([]models.BatchProgram, config.BatchConcurrency)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, job.ID)
+	}
+
+	b.waitStarted(t, config.BatchConcurrency)
+	// Give workers the chance to start more programs than allowed.
+	time.Sleep(10 * time.Millisecond)
+	b.mu.Lock()
+	running := b.running
+	b.mu.Unlock()
+	if running != config.BatchConcurrency {
+		t.Errorf("%d programs running, want %d", running, config.BatchConcurrency)
+	}
+
+	close(b.release)
+	for _, id := range ids {
+		waitStatus(t, q, id, models.BatchDone)
+	}
+	if b.max != config.BatchConcurrency {
+		t.Errorf("at most %d programs ran at once, want %d", b.max, config.BatchConcurrency)
+	}
+}
+
+func TestSubmitBusy(t *testing.T) {
+	b := newBlocker()
+	q := newQueue(b.run)
+	defer q.Close()
+
+	var first models.BatchJob
+	for i := 0; i < config.MaxBatchJobs; i++ {
+		job, err := q.Submit(models.BatchRequest{Programs: programs("a")})
+		if err != nil {
+			t.Fatalf("job %d: %v", i, err)
+		}
+		if i == 0 {
+			first = job
+		}
+	}
+	if _, err := q.Submit(models.BatchRequest{Programs: programs("a")}); !errors.Is(err, ErrBusy) {
+		t.Fatalf("Submit over the job limit = %v, want ErrBusy", err)
+	}
+
+	// Cancelled jobs are finished and leave room for new ones.
+	if _, err := q.Cancel(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Submit(models.BatchRequest{Programs: programs("a")}); err != nil {
+		t.Errorf("Submit after cancelling a job = %v", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	b := newBlocker()
+	q := newQueue(b.run)
+	defer q.Close()
+
+	job, err := q.Submit(models.BatchRequest{Programs: make([]models.BatchProgram, config.BatchConcurrency+2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.waitStarted(t, config.BatchConcurrency)
+	if job, _ := q.Job(job.ID, false); job.Status != models.BatchRunning {
+		t.Errorf("status = %s, want %s", job.Status, models.BatchRunning)
+	}
+
+	cancelled, err := q.Cancel(job.ID)
+	if err != nil || cancelled.Status != models.BatchCancelled || cancelled.FinishedAt == nil {
+		t.Fatalf("Cancel = %+v, %v; want a finished, cancelled job", cancelled, err)
+	}
+
+	// Running programs return once cancelled and are not recorded; the
+	// others never start.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b.mu.Lock()
+		running := b.running
+		b.mu.Unlock()
+		if running == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d programs still running", running)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	job, err = q.Job(job.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.BatchCancelled || job.Completed != 0 {
+		t.Errorf("job = %+v, want cancelled with nothing completed", job)
+	}
+	for i, result := range job.Results {
+		if result.Status != models.ProgramSkipped {
+			t.Errorf("result %d is %s, want %s", i, result.Status, models.ProgramSkipped)
+		}
+	}
+	if len(b.started) != 0 {
+		t.Errorf("%d programs started after the job was cancelled", len(b.started))
+	}
+
+	if again, err := q.Cancel(job.ID); err != nil || *again.FinishedAt != *cancelled.FinishedAt {
+		t.Errorf("cancelling again = %+v, %v; want the job unchanged", again, err)
+	}
+	if _, err := q.Cancel("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel of an unknown job = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCancelFinishedJob(t *testing.T) {
+	q := newQueue(echo)
+	defer q.Close()
+
+	job, err := q.Submit(models.BatchRequest{Programs: programs("a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitStatus(t, q, job.ID, models.BatchDone)
+	if job, err := q.Cancel(job.ID); err != nil || job.Status != models.BatchDone {
+		t.Errorf("Cancel of a finished job = %s, %v; want it left done", job.Status, err)
+	}
+}
+
+func TestForget(t *testing.T) {
+	b := newBlocker()
+	q := newQueue(b.run)
+	defer q.Close()
+
+	finished, err := q.Submit(models.BatchRequest{Programs: programs("a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.waitStarted(t, 1)
+	if _, err := q.Cancel(finished.ID); err != nil {
+		t.Fatal(err)
+	}
+	running, err := q.Submit(models.BatchRequest{Programs: programs("a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q.forget(time.Now().Add(-time.Minute))
+	if _, err := q.Job(finished.ID, false); err != nil {
+		t.Errorf("job finished after the cutoff was forgotten: %v", err)
+	}
+
+	q.forget(time.Now().Add(time.Minute))
+	if _, err := q.Job(finished.ID, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Job of a forgotten job = %v, want ErrNotFound", err)
+	}
+	if _, err := q.Job(running.ID, false); err != nil {
+		t.Errorf("unfinished job was forgotten: %v", err)
+	}
+}
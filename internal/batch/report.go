@@ -0,0 +1,40 @@
+package batch
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// reportHeader names the columns of CSV reports.
+var reportHeader = []string{"name", "status", "duration_ms", "error", "output", "stderr"}
+
+// WriteCSV writes the results of job as CSV, one row per program in the
+// order they were submitted.
+func WriteCSV(w io.Writer, job models.BatchJob) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reportHeader); err != nil {
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+	for _, result := range job.Results {
+		row := []string{
+			result.Name,
+			result.Status,
+			strconv.FormatInt(result.DurationMs, 10),
+			result.Error,
+			result.Output,
+			result.Stderr,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write report: %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package batch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		job  models.BatchJob
+		want string
+	}{
+		{"no results", models.BatchJob{}, "name,status,duration_ms,error,output,stderr\n"},
+		{
+			"results",
+			models.BatchJob{Results: []models.BatchResult{
+				{Name: "b", Status: models.ProgramPassed, Output: "hello\n", DurationMs: 12},
+				{Name: "a", Status: models.ProgramFailed, Error: "exit status 2", Stderr: `panic: "x", y`, DurationMs: 3},
+				{Name: "c", Status: models.ProgramSkipped},
+			}},
+			"name,status,duration_ms,error,output,stderr\n" +
+				"b,passed,12,,\"hello\n\",\n" +
+				"a,failed,3,exit status 2,,\"panic: \"\"x\"\", y\"\n" +
+				"c,skipped,0,,,\n",
+		},
+	}
+	for _, test := range tests {
+		var b strings.Builder
+		if err := WriteCSV(&b, test.job); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if b.String() != test.want {
+			t.Errorf("%s: report =\n%s\nwant\n%s", test.name, b.String(), test.want)
+		}
+	}
+}
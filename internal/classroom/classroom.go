@@ -0,0 +1,489 @@
+package classroom
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+var (
+	// ErrNotFound is returned for rooms that do not exist or were closed.
+	ErrNotFound = errors.New("room not found")
+	// ErrForbidden is returned for invalid instructor or student tokens.
+	ErrForbidden = errors.New("invalid room token")
+	// ErrFull is returned when there are too many rooms or students.
+	ErrFull = errors.New("room limit reached")
+	// ErrTooLarge is returned for code larger than config.MaxCodeSize.
+	ErrTooLarge = fmt.Errorf("code exceeds %d bytes", config.MaxCodeSize)
+)
+
+// codeAlphabet leaves out letters and digits that are easily confused when
+// a room code is read aloud or off a projector.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Rooms holds the open classroom rooms in memory.
+type Rooms struct {
+	mu    sync.Mutex
+	rooms map[string]*Room
+	done  chan struct{}
+}
+
+// NewRooms returns an empty set of rooms and starts closing the idle ones.
+func NewRooms() *Rooms {
+	rs := &Rooms{
+		rooms: make(map[string]*Room),
+		done:  make(chan struct{}),
+	}
+	go rs.reap()
+	return rs
+}
+
+// Create opens a room with starterCode and returns it with the instructor's
+// token.
+func (rs *Rooms) Create(starterCode string) (*Room, string, error) {
+	if len(starterCode) > config.MaxCodeSize {
+		return nil, "", ErrTooLarge
+	}
+	token, err := randomToken(18)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if len(rs.rooms) >= config.MaxRooms {
+		return nil, "", ErrFull
+	}
+	var code string
+	for code == "" || rs.rooms[code] != nil {
+		if code, err = roomCode(6); err != nil {
+			return nil, "", err
+		}
+	}
+
+	now := time.Now()
+	room := &Room{
+		code:            code,
+		instructorToken: token,
+		starterCode:     starterCode,
+		createdAt:       now,
+		activeAt:        now,
+		students:        make(map[string]*student),
+		subscribers:     make(map[*Subscriber]bool),
+	}
+	rs.rooms[code] = room
+	return room, token, nil
+}
+
+// Get returns the room with the given code, which is case insensitive.
+func (rs *Rooms) Get(code string) (*Room, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	room, ok := rs.rooms[strings.ToUpper(code)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return room, nil
+}
+
+// Remove closes a room, ending its subscribers' streams.
+func (rs *Rooms) Remove(room *Room) {
+	rs.mu.Lock()
+	delete(rs.rooms, room.code)
+	rs.mu.Unlock()
+
+	room.close()
+}
+
+// Close stops closing idle rooms.
+func (rs *Rooms) Close() {
+	close(rs.done)
+}
+
+// reap closes rooms idle for config.RoomIdleHours.
+func (rs *Rooms) reap() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rs.done:
+			return
+		case <-ticker.C:
+		}
+		rs.closeIdle(time.Now().Add(-config.RoomIdleHours * time.Hour))
+	}
+}
+
+// closeIdle closes the rooms last active before cutoff.
+func (rs *Rooms) closeIdle(cutoff time.Time) {
+	var idle []*Room
+	rs.mu.Lock()
+	for _, room := range rs.rooms {
+		if room.idleSince(cutoff) {
+			idle = append(idle, room)
+		}
+	}
+	rs.mu.Unlock()
+
+	for _, room := range idle {
+		rs.Remove(room)
+	}
+}
+
+// Room is a classroom room: an instructor, the students who joined with its
+// code, and the streams of events they subscribed to.
+type Room struct {
+	code            string
+	instructorToken string
+
+	mu          sync.Mutex
+	starterCode string
+	broadcasts  []models.RoomBroadcast
+	students    map[string]*student
+	subscribers map[*Subscriber]bool
+	createdAt   time.Time
+	activeAt    time.Time
+	closed      bool
+}
+
+type student struct {
+	models.RoomStudent
+	token string
+	// runs numbers the student's runs, so that output of a run replaced
+	// by a newer one is ignored.
+	runs int
+}
+
+// Subscriber receives the events of a room. Events is closed when the room
+// closes, or when the subscriber falls too far behind; it then has to
+// subscribe again to get the room's current state.
+type Subscriber struct {
+	Events     chan models.RoomEvent
+	instructor bool
+}
+
+// Code returns the code students join the room with.
+func (r *Room) Code() string {
+	return r.code
+}
+
+// StarterCode returns the code students start from.
+func (r *Room) StarterCode() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.starterCode
+}
+
+// Instructor checks token against the instructor's.
+func (r *Room) Instructor(token string) error {
+	if subtle.ConstantTimeCompare([]byte(token), []byte(r.instructorToken)) != 1 {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Student returns the ID of the student with token.
+func (r *Room) Student(token string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, s := range r.students {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return id, nil
+		}
+	}
+	return "", ErrForbidden
+}
+
+// Join adds a student named name to the room and returns their ID and
+// token.
+func (r *Room) Join(name string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > config.MaxStudentNameLength {
+		return "", "", fmt.Errorf("names must have between 1 and %d characters", config.MaxStudentNameLength)
+	}
+	id, err := randomToken(6)
+	if err != nil {
+		return "", "", err
+	}
+	token, err := randomToken(18)
+	if err != nil {
+		return "", "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return "", "", ErrNotFound
+	}
+	if len(r.students) >= config.MaxRoomStudents {
+		return "", "", ErrFull
+	}
+
+	now := time.Now()
+	s := &student{
+		RoomStudent: models.RoomStudent{ID: id, Name: name, Code: r.starterCode, UpdatedAt: now},
+		token:       token,
+	}
+	r.students[id] = s
+	r.activeAt = now
+	r.notifyStudent(s)
+	return id, token, nil
+}
+
+// UpdateCode records the latest code of a student.
+func (r *Room) UpdateCode(id, code string) error {
+	if len(code) > config.MaxCodeSize {
+		return ErrTooLarge
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.students[id]
+	if !ok {
+		return ErrForbidden
+	}
+	if s.Code == code {
+		return nil
+	}
+	s.Code = code
+	s.UpdatedAt = time.Now()
+	r.activeAt = s.UpdatedAt
+	r.notifyStudent(s)
+	return nil
+}
+
+// StartRun records that a student runs code and returns the observer to set
+// on the run's session, which records its outcome.
+func (r *Room) StartRun(id, code string) (func(models.ProgramOutput), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.students[id]
+	if !ok {
+		return nil, ErrForbidden
+	}
+	s.runs++
+	run := s.runs
+	now := time.Now()
+	s.Code = code
+	s.UpdatedAt = now
+	s.Run = &models.RoomRun{StartedAt: now}
+	r.activeAt = now
+	r.notifyStudent(s)
+
+	return func(output models.ProgramOutput) {
+		r.recordOutput(id, run, output)
+	}, nil
+}
+
+func (r *Room) recordOutput(id string, run int, output models.ProgramOutput) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.students[id]
+	if !ok || s.runs != run || s.Run == nil || s.Run.FinishedAt != nil {
+		return
+	}
+
+	if left := config.MaxRoomOutputSize - len(s.Run.Output); left > 0 {
+		text := output.Output
+		if len(text) > left {
+			text = text[:left]
+		}
+		s.Run.Output += text
+	}
+	// Only finished runs are sent, so that chatty programs do not flood
+	// the instructor.
+	if output.Done || output.Error != "" {
+		now := time.Now()
+		s.Run.FinishedAt = &now
+		s.Run.Error = output.Error
+		r.notifyStudent(s)
+	}
+}
+
+// SetStarterCode replaces the starter code and pushes it to every student.
+func (r *Room) SetStarterCode(code string) error {
+	if len(code) > config.MaxCodeSize {
+		return ErrTooLarge
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.starterCode = code
+	r.activeAt = time.Now()
+	r.notify(models.RoomEvent{Type: models.RoomEventStarter, Code: code}, false)
+	return nil
+}
+
+// Broadcast sends code, with a message, to every student.
+func (r *Room) Broadcast(code, message string) error {
+	if len(code)+len(message) > config.MaxCodeSize {
+		return ErrTooLarge
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	broadcast := models.RoomBroadcast{Code: code, Message: message, SentAt: time.Now()}
+	r.broadcasts = append(r.broadcasts, broadcast)
+	if len(r.broadcasts) > config.MaxRoomBroadcasts {
+		r.broadcasts = r.broadcasts[len(r.broadcasts)-config.MaxRoomBroadcasts:]
+	}
+	r.activeAt = broadcast.SentAt
+	r.notify(models.RoomEvent{Type: models.RoomEventBroadcast, Broadcast: &broadcast}, false)
+	return nil
+}
+
+// Snapshot returns the room as the instructor sees it, or as students do.
+func (r *Room) Snapshot(instructor bool) models.Room {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshot(instructor)
+}
+
+func (r *Room) snapshot(instructor bool) models.Room {
+	room := models.Room{
+		Code:        r.code,
+		StarterCode: r.starterCode,
+		Broadcasts:  append([]models.RoomBroadcast{}, r.broadcasts...),
+		CreatedAt:   r.createdAt,
+	}
+	if instructor {
+		room.Students = []models.RoomStudent{}
+		for _, s := range r.students {
+			room.Students = append(room.Students, s.view())
+		}
+		sort.Slice(room.Students, func(i, j int) bool {
+			return room.Students[i].Name < room.Students[j].Name
+		})
+	}
+	return room
+}
+
+// Subscribe returns a subscriber to the room's events, starting with its
+// current state, and a function ending the subscription.
+func (r *Room) Subscribe(instructor bool) (*Subscriber, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, nil, ErrNotFound
+	}
+
+	sub := &Subscriber{
+		Events:     make(chan models.RoomEvent, 64),
+		instructor: instructor,
+	}
+	room := r.snapshot(instructor)
+	sub.Events <- models.RoomEvent{Type: models.RoomEventRoom, Room: &room}
+	r.subscribers[sub] = true
+
+	unsubscribe := func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.drop(sub)
+	}
+	return sub, unsubscribe, nil
+}
+
+// notifyStudent sends the state of s to the instructor. r.mu must be held.
+func (r *Room) notifyStudent(s *student) {
+	view := s.view()
+	r.notify(models.RoomEvent{Type: models.RoomEventStudent, Student: &view}, true)
+}
+
+// notify sends event to the subscribers, only the instructor's if
+// instructorOnly is set. Subscribers that fall behind are dropped. r.mu must
+// be held.
+func (r *Room) notify(event models.RoomEvent, instructorOnly bool) {
+	for sub := range r.subscribers {
+		if instructorOnly && !sub.instructor {
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			r.drop(sub)
+		}
+	}
+}
+
+// drop ends a subscription. r.mu must be held.
+func (r *Room) drop(sub *Subscriber) {
+	if r.subscribers[sub] {
+		delete(r.subscribers, sub)
+		close(sub.Events)
+	}
+}
+
+func (r *Room) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	for sub := range r.subscribers {
+		select {
+		case sub.Events <- models.RoomEvent{Type: models.RoomEventClosed}:
+		default:
+		}
+		r.drop(sub)
+	}
+}
+
+func (r *Room) idleSince(cutoff time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.activeAt.Before(cutoff)
+}
+
+// view copies the student's state, which changes under the room's lock.
+func (s *student) view() models.RoomStudent {
+	view := s.RoomStudent
+	if s.Run != nil {
+		run := *s.Run
+		view.Run = &run
+	}
+	return view
+}
+
+// roomCode returns a random room code of n characters from codeAlphabet.
+func roomCode(n int) (string, error) {
+	code := make([]byte, n)
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := range code {
+		index, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate room code: %v", err)
+		}
+		code[i] = codeAlphabet[index.Int64()]
+	}
+	return string(code), nil
+}
+
+// randomToken returns n random bytes, encoded for use in URLs.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,322 @@
+package classroom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+func newRoom(t *testing.T, starterCode string) (*Rooms, *Room) {
+	t.Helper()
+	rooms := NewRooms()
+	t.Cleanup(rooms.Close)
+	room, _, err := rooms.Create(starterCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rooms, room
+}
+
+// next returns the next event of sub, failing if there is none.
+func next(t *testing.T, sub *Subscriber) models.RoomEvent {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events:
+		if !ok {
+			t.Fatal("subscription ended")
+		}
+		return event
+	default:
+		t.Fatal("no event")
+	}
+	return models.RoomEvent{}
+}
+
+// none fails if sub has an event waiting.
+func none(t *testing.T, sub *Subscriber) {
+	t.Helper()
+	select {
+	case event := <-sub.Events:
+		t.Errorf("unexpected %s event", event.Type)
+	default:
+	}
+}
+
+func TestCreateAndGet(t *testing.T) {
+	rooms := NewRooms()
+	defer rooms.Close()
+
+	if _, _, err := rooms.Create(strings.Repeat("x", config.MaxCodeSize+1)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Create with large starter code = %v, want ErrTooLarge", err)
+	}
+
+	room, token, err := rooms.Create("package main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(room.Code()) != 6 || strings.ContainsAny(room.Code(), "01IO") {
+		t.Errorf("room code %q, want 6 unambiguous characters", room.Code())
+	}
+	if got, err := rooms.Get(strings.ToLower(room.Code())); err != nil || got != room {
+		t.Errorf("Get of the lower case code = %v, %v", got, err)
+	}
+	if room.Instructor(token) != nil || !errors.Is(room.Instructor("x"+token), ErrForbidden) {
+		t.Error("instructor token not checked")
+	}
+
+	rooms.Remove(room)
+	if _, err := rooms.Get(room.Code()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a removed room = %v, want ErrNotFound", err)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	_, room := newRoom(t, "package main")
+
+	tests := []struct {
+		name    string
+		student string
+		wantErr bool
+	}{
+		{"empty", "  ", true},
+		{"too long", strings.Repeat("é", config.MaxStudentNameLength+1), true},
+		{"longest", strings.Repeat("é", config.MaxStudentNameLength), false},
+		{"trimmed", " Ann ", false},
+	}
+	for _, test := range tests {
+		if _, _, err := room.Join(test.student); (err != nil) != test.wantErr {
+			t.Errorf("%s: Join = %v, want error %v", test.name, err, test.wantErr)
+		}
+	}
+
+	id, token, err := room.Join("Bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := room.Student(token); err != nil || got != id {
+		t.Errorf("Student(token) = %q, %v; want %q", got, err, id)
+	}
+	if _, err := room.Student("nope"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Student of an unknown token = %v, want ErrForbidden", err)
+	}
+
+	snapshot := room.Snapshot(true)
+	var names []string
+	for _, s := range snapshot.Students {
+		names = append(names, s.Name)
+		if s.Code != "package main" {
+			t.Errorf("%s starts with %q, want the starter code", s.Name, s.Code)
+		}
+	}
+	if want := []string{"Ann", "Bob", strings.Repeat("é", config.MaxStudentNameLength)}; strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("students %v, want %v", names, want)
+	}
+	if students := room.Snapshot(false).Students; students != nil {
+		t.Errorf("students see %v, want no other students", students)
+	}
+}
+
+func TestJoinLimits(t *testing.T) {
+	rooms, room := newRoom(t, "")
+	for i := 0; i < config.MaxRoomStudents; i++ {
+		if _, _, err := room.Join("student"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := room.Join("late"); !errors.Is(err, ErrFull) {
+		t.Errorf("Join of a full room = %v, want ErrFull", err)
+	}
+
+	rooms.Remove(room)
+	if _, _, err := room.Join("late"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Join of a closed room = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateCode(t *testing.T) {
+	_, room := newRoom(t, "package main")
+	id, _, err := room.Join("Ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instructor, _, err := room.Subscribe(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	student, _, err := room.Subscribe(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next(t, instructor)
+	next(t, student)
+
+	tests := []struct {
+		name    string
+		id      string
+		code    string
+		wantErr error
+		event   bool
+	}{
+		{"unknown student", "nope", "x", ErrForbidden, false},
+		{"too large", id, strings.Repeat("x", config.MaxCodeSize+1), ErrTooLarge, false},
+		{"unchanged", id, "package main", nil, false},
+		{"changed", id, "package main\n\nfunc main() {}\n", nil, true},
+	}
+	for _, test := range tests {
+		if err := room.UpdateCode(test.id, test.code); !errors.Is(err, test.wantErr) {
+			t.Errorf("%s: UpdateCode = %v, want %v", test.name, err, test.wantErr)
+		}
+		if !test.event {
+			none(t, instructor)
+			continue
+		}
+		event := next(t, instructor)
+		if event.Type != models.RoomEventStudent || event.Student.Code != test.code {
+			t.Errorf("%s: event %+v, want the student's new code", test.name, event)
+		}
+	}
+	// Students do not see each other's code.
+	none(t, student)
+}
+
+func TestRuns(t *testing.T) {
+	_, room := newRoom(t, "")
+	id, _, err := room.Join("Ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := room.StartRun("nope", "x"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("StartRun of an unknown student = %v, want ErrForbidden", err)
+	}
+	instructor, _, err := room.Subscribe(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next(t, instructor)
+
+	stale, err := room.StartRun(id, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event := next(t, instructor); event.Student.Run == nil || event.Student.Run.FinishedAt != nil || event.Student.Code != "first" {
+		t.Errorf("event %+v, want a started run of the code", event.Student)
+	}
+	observe, err := room.StartRun(id, "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next(t, instructor)
+
+	// Output of the replaced run is ignored.
+	stale(models.ProgramOutput{Output: "old\n", Done: true})
+	none(t, instructor)
+
+	// Only finished runs are sent, with their output capped.
+	observe(models.ProgramOutput{Output: "hello\n"})
+	observe(models.ProgramOutput{Output: strings.Repeat("x", config.MaxRoomOutputSize)})
+	none(t, instructor)
+	observe(models.ProgramOutput{Error: "exit status 1", Done: true})
+	run := next(t, instructor).Student.Run
+	if run == nil || run.FinishedAt == nil || run.Error != "exit status 1" {
+		t.Fatalf("run %+v, want finished with its error", run)
+	}
+	if len(run.Output) != config.MaxRoomOutputSize || !strings.HasPrefix(run.Output, "hello\nxxx") {
+		t.Errorf("output of %d bytes starting %q, want %d bytes", len(run.Output), run.Output[:10], config.MaxRoomOutputSize)
+	}
+
+	// A finished run is not changed again.
+	observe(models.ProgramOutput{Output: "late", Done: true})
+	none(t, instructor)
+}
+
+func TestSubscribers(t *testing.T) {
+	rooms, room := newRoom(t, "package main")
+	instructor, _, err := room.Subscribe(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	student, unsubscribe, err := room.Subscribe(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event := next(t, student); event.Type != models.RoomEventRoom || event.Room.StarterCode != "package main" {
+		t.Errorf("first event %+v, want the room", event)
+	}
+	next(t, instructor)
+
+	if err := room.SetStarterCode("package other"); err != nil {
+		t.Fatal(err)
+	}
+	if err := room.Broadcast("package main", "look"); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []*Subscriber{instructor, student} {
+		if event := next(t, sub); event.Type != models.RoomEventStarter || event.Code != "package other" {
+			t.Errorf("event %+v, want the new starter code", event)
+		}
+		if event := next(t, sub); event.Type != models.RoomEventBroadcast || event.Broadcast.Message != "look" {
+			t.Errorf("event %+v, want the broadcast", event)
+		}
+	}
+
+	unsubscribe()
+	if _, ok := <-student.Events; ok {
+		t.Error("events still open after unsubscribing")
+	}
+	unsubscribe()
+
+	// Subscribers that fall behind are dropped.
+	for i := 0; i <= cap(instructor.Events); i++ {
+		if err := room.Broadcast("", "spam"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range instructor.Events {
+	}
+	if broadcasts := room.Snapshot(false).Broadcasts; len(broadcasts) != config.MaxRoomBroadcasts {
+		t.Errorf("%d broadcasts kept, want %d", len(broadcasts), config.MaxRoomBroadcasts)
+	}
+
+	// Closing the room ends every subscription with a closed event.
+	sub, _, err := room.Subscribe(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next(t, sub)
+	rooms.Remove(room)
+	if event := next(t, sub); event.Type != models.RoomEventClosed {
+		t.Errorf("event %+v, want closed", event)
+	}
+	if _, ok := <-sub.Events; ok {
+		t.Error("events still open after the room closed")
+	}
+	if _, _, err := room.Subscribe(true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Subscribe to a closed room = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCloseIdle(t *testing.T) {
+	rooms, idle := newRoom(t, "")
+	active, _, err := rooms.Create("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(time.Millisecond)
+	if _, _, err := active.Join("Ann"); err != nil {
+		t.Fatal(err)
+	}
+
+	rooms.closeIdle(cutoff)
+	if _, err := rooms.Get(idle.Code()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("idle room still open: %v", err)
+	}
+	if _, err := rooms.Get(active.Code()); err != nil {
+		t.Errorf("active room was closed: %v", err)
+	}
+}
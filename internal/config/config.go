@@ -10,9 +10,102 @@ const (
 	TimeoutSeconds = 100
 	MemoryLimit    = 150 * 1024 * 1024
 
+	// GoVersion is the language version of the sandbox toolchain, used when
+	// type checking outside of it.
+	GoVersion = "go1.22"
+
+	// Race detector sandbox. The race runtime needs cgo and glibc, so it
+	// cannot run in the alpine image above.
+	RaceDockerImage   = "golang:1.22-bookworm"
+	RaceContainerName = "go-playground-race"
+	RaceMemoryLimit   = 512 * 1024 * 1024
+
+	// SelfTestContainerName is the sandbox the selftest command runs the
+	// examples in, apart from the server's.
+	SelfTestContainerName = "go-playground-selftest"
+
+	// Debugger and language server sandbox, built from
+	// sandbox/tools.Dockerfile. Delve needs ptrace, which is only granted to
+	// this container.
+	ToolsDockerImage    = "go-playground-tools:1.22"
+	ToolsContainerName  = "go-playground-tools"
+	DebugTimeoutMinutes = 10
+	ToolsMemoryLimit    = 1024 * 1024 * 1024
+
+	// Language server. Editors address the session's workspace by
+	// LSPRootURI; gopls sees its real location inside the tools container.
+	LSPRootURI        = "file:///playground"
+	LSPTimeoutMinutes = 60
+	MaxLSPSessions    = 4
+	MaxLSPMessageSize = 4 * 1024 * 1024
+
+	// Hidden exercise tests are stopped after TestTimeoutSeconds.
+	TestTimeoutSeconds = 30
+
+	// Batch runs. A job holds at most MaxBatchPrograms programs and at most
+	// MaxBatchJobs jobs may be unfinished at once; BatchConcurrency programs
+	// of all jobs run at a time. Each result keeps MaxBatchOutputSize bytes
+	// of output, and finished jobs are kept for BatchJobTTLMinutes.
+	MaxBatchPrograms    = 500
+	MaxBatchJobs        = 10
+	BatchConcurrency    = 4
+	MaxBatchOutputSize  = 64 * 1024
+	MaxBatchRequestSize = 64 * 1024 * 1024
+	BatchJobTTLMinutes  = 24 * 60
+
+	// Classroom rooms. A room holds at most MaxRoomStudents students and
+	// keeps its last MaxRoomBroadcasts broadcasts and MaxRoomOutputSize
+	// bytes of each student's run. Rooms idle for RoomIdleHours are closed.
+	MaxRooms             = 100
+	MaxRoomStudents      = 100
+	MaxRoomBroadcasts    = 20
+	MaxRoomOutputSize    = 16 * 1024
+	MaxStudentNameLength = 40
+	RoomIdleHours        = 12
+
+	// Similarity analysis compares programs by SimilarityKGram-node
+	// sequences of their syntax trees, winnowed over SimilarityWindow
+	// hashes. Pairs at least SimilarityThreshold similar are reported, and
+	// programs with fewer than MinSimilarityFingerprints fingerprints are
+	// too short to compare.
+	SimilarityKGram           = 12
+	SimilarityWindow          = 4
+	SimilarityThreshold       = 0.8
+	MinSimilarityFingerprints = 10
+
 	// Program limits
 	MaxCodeSize   = 1024 * 1024
 	MaxOutputSize = 1024 * 1024
+	// MaxRequestSize bounds JSON request bodies carrying code, leaving room
+	// for escaping.
+	MaxRequestSize = 4 * MaxCodeSize
+
+	// Profiling and tracing
+	ProfileTopN    = 20
+	MaxProfileSize = 4 * 1024 * 1024
+	MaxTraceSize   = 4 * 1024 * 1024
+
+	// Shared snippets. SnippetBackend is "filesystem" (stored under
+	// SnippetDir), "bolt" (stored in SnippetDBFile) or "memory".
+	SnippetBackend = "filesystem"
+	SnippetDir     = "data/snippets"
+	SnippetDBFile  = "data/snippets.db"
+	// MaxSnippetLineage bounds how many ancestors a history lists.
+	MaxSnippetLineage = 1000
+	// Snippets expire after DefaultSnippetTTLHours, or
+	// PrivateSnippetTTLHours if encrypted, unless the sharer asks otherwise.
+	// Requested lifetimes are capped at MaxSnippetTTLHours.
+	DefaultSnippetTTLHours = 90 * 24
+	PrivateSnippetTTLHours = 7 * 24
+	MaxSnippetTTLHours     = 365 * 24
+	// Expired snippets are deleted every SnippetReapIntervalMinutes.
+	SnippetReapIntervalMinutes = 10
+	// Exported programs are zipped as a module named ExportModulePath, with
+	// at most MaxExportFiles files besides the generated ones.
+	ExportModulePath = "playground"
+	MaxExportFiles   = 20
+	// MaxCodeParamSize bounds the compressed code a home page link may carry.
+	MaxCodeParamSize = 16 * 1024
 
 	// Rate limiting
 	RequestsPerHour   = 1000
@@ -20,6 +113,12 @@ const (
 )
 
 var (
+	// EmbedAllowedOrigins lists the sites, such as a course wiki, allowed to
+	// frame /embed pages besides the playground itself, as origins like
+	// "https://wiki.example.com". Other pages may only be framed by the
+	// playground.
+	EmbedAllowedOrigins = []string{}
+
 	// Security configuration
 DisallowedPatterns = []string{
         // Dangerous imports
@@ -53,7 +152,6 @@ DisallowedPatterns = []string{
         
         // Command execution
         `\bexec\.Command\b`,
-        `\bgo\s+func\b`,              // Prevent goroutine spawning
         
         // Memory operations
         `\(*\[\]byte\)\(`,
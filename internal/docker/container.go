@@ -22,6 +22,8 @@ type ContainerConfig struct {
 	Image       string
 	MemoryLimit int64
 	WorkDir     string
+	Env         []string
+	CapAdd      []string
 }
 
 func NewContainer(config ContainerConfig) (*Container, error) {
@@ -40,6 +42,18 @@ func (c *Container) Close() error {
 	return c.client.Close()
 }
 
+// Remove force-removes the container started by Ensure.
+func (c *Container) Remove() error {
+	if c.ID == "" {
+		return nil
+	}
+	if err := c.client.ContainerRemove(context.Background(), c.ID, container.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("failed to remove container: %v", err)
+	}
+	c.ID = ""
+	return nil
+}
+
 func (c *Container) Ensure() error {
 	ctx := context.Background()
 	log.Println("Checking for existing container...")
@@ -75,11 +89,7 @@ func (c *Container) Ensure() error {
 		Image:      c.config.Image,
 		Cmd:        []string{"sh", "-c", "while true; do sleep 1; done"},
 		WorkingDir: c.config.WorkDir,
-		Env: []string{
-			"GOMEMLIMIT=50MiB",
-			"GOGC=50",
-			"CGO_ENABLED=0",
-		},
+		Env:        c.config.Env,
 	}
 
 	pidsLimit := int64(100)
@@ -93,6 +103,7 @@ func (c *Container) Ensure() error {
 		NetworkMode: "none",
 		AutoRemove:  false,
 		SecurityOpt: []string{"no-new-privileges"},
+		CapAdd:      c.config.CapAdd,
 	}
 
 	resp, err := c.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, c.config.Name)
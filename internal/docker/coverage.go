@@ -0,0 +1,103 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// coverBlockRe matches a block line of `go tool covdata textfmt` output:
+// file:startLine.startCol,endLine.endCol numStatements count
+var coverBlockRe = regexp.MustCompile(`^(.+):(\d+)\.\d+,(\d+)\.(\d+) (\d+) (\d+)$`)
+
+// collectCoverage converts the coverage counters written to dir into per-line
+// counts for the submitted files and removes dir.
+func (e *Executor) collectCoverage(ctx context.Context, c *Container, dir string) *models.CoverageResult {
+	script := fmt.Sprintf("go tool covdata textfmt -i=%s -o=/dev/stdout; rm -rf %s", dir, dir)
+	stdout, stderr, exitCode, err := e.execOutput(ctx, c, []string{"sh", "-c", script})
+	if err != nil {
+		log.Printf("Failed to collect coverage: %v\n", err)
+		return nil
+	}
+	if exitCode != 0 {
+		log.Printf("Failed to convert coverage data: %s\n", stderr)
+		return nil
+	}
+	return parseCoverage(stdout, []string{"main.go"})
+}
+
+// parseCoverage turns textfmt coverage profiles into line counts, keeping only
+// files whose base name is in files. A line's count is the highest count of
+// any block that spans it.
+func parseCoverage(profile string, files []string) *models.CoverageResult {
+	result := &models.CoverageResult{}
+	lineCounts := make(map[string]map[int]int64)
+	coverage := make(map[string]*models.FileCoverage)
+
+	for _, line := range strings.Split(profile, "\n") {
+		if mode, ok := strings.CutPrefix(line, "mode: "); ok {
+			result.Mode = mode
+			continue
+		}
+
+		m := coverBlockRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := path.Base(m[1])
+		if !slices.Contains(files, name) {
+			continue
+		}
+		startLine, _ := strconv.Atoi(m[2])
+		endLine, _ := strconv.Atoi(m[3])
+		endCol, _ := strconv.Atoi(m[4])
+		statements, _ := strconv.Atoi(m[5])
+		count, _ := strconv.ParseInt(m[6], 10, 64)
+
+		// A block ending in column 1 stops before the first character of
+		// its last line, so that line belongs to the next block.
+		if endCol <= 1 && endLine > startLine {
+			endLine--
+		}
+
+		fc, ok := coverage[name]
+		if !ok {
+			fc = &models.FileCoverage{Name: name}
+			coverage[name] = fc
+			lineCounts[name] = make(map[int]int64)
+		}
+		fc.Statements += statements
+		if count > 0 {
+			fc.Covered += statements
+		}
+
+		counts := lineCounts[name]
+		for l := startLine; l <= endLine; l++ {
+			if prev, seen := counts[l]; !seen || count > prev {
+				counts[l] = count
+			}
+		}
+	}
+
+	for _, name := range files {
+		fc, ok := coverage[name]
+		if !ok {
+			continue
+		}
+		for l, count := range lineCounts[name] {
+			fc.Lines = append(fc.Lines, models.LineCoverage{Line: l, Count: count})
+		}
+		sort.Slice(fc.Lines, func(i, j int) bool { return fc.Lines[i].Line < fc.Lines[j].Line })
+		result.Files = append(result.Files, *fc)
+	}
+
+	return result
+}
@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+func TestParseCoverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		want    *models.CoverageResult
+	}{
+		{"empty", "", &models.CoverageResult{}},
+		{
+			// Output of go tool covdata textfmt for a loop with an if/else
+			// and a branch that never runs.
+			"loop",
+			`mode: count
+command-line-arguments/main.go:6.2,6.25 1 1
+command-line-arguments/main.go:7.3,7.15 1 3
+command-line-arguments/main.go:8.4,9.1 1 2
+command-line-arguments/main.go:10.4,11.1 1 1
+command-line-arguments/main.go:13.2,13.11 1 1
+command-line-arguments/main.go:14.3,15.1 1 0
+`,
+			&models.CoverageResult{Mode: "count", Files: []models.FileCoverage{{
+				Name:       "main.go",
+				Statements: 6,
+				Covered:    5,
+				Lines: []models.LineCoverage{
+					{Line: 6, Count: 1}, {Line: 7, Count: 3}, {Line: 8, Count: 2},
+					{Line: 10, Count: 1}, {Line: 13, Count: 1}, {Line: 14, Count: 0},
+				},
+			}}},
+		},
+		{
+			// Overlapping blocks keep the highest count; blocks ending past
+			// column 1 keep their last line.
+			"overlap",
+			"mode: count\nmain.go:3.10,5.2 2 0\nmain.go:4.5,4.20 1 7\n",
+			&models.CoverageResult{Mode: "count", Files: []models.FileCoverage{{
+				Name:       "main.go",
+				Statements: 3,
+				Covered:    1,
+				Lines:      []models.LineCoverage{{Line: 3, Count: 0}, {Line: 4, Count: 7}, {Line: 5, Count: 0}},
+			}}},
+		},
+		{
+			// The generated wrapper and malformed lines are ignored.
+			"other files",
+			"mode: set\ncommand-line-arguments/playground_wrapper.go:10.2,12.3 2 1\ngarbage\nmain.go:1.1,1.10 1 1\n",
+			&models.CoverageResult{Mode: "set", Files: []models.FileCoverage{{
+				Name:       "main.go",
+				Statements: 1,
+				Covered:    1,
+				Lines:      []models.LineCoverage{{Line: 1, Count: 1}},
+			}}},
+		},
+	}
+	for _, test := range tests {
+		if got := parseCoverage(test.profile, []string{"main.go"}); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// debugCommands maps the commands accepted from clients to Delve's names.
+var debugCommands = map[string]string{
+	"continue": "continue",
+	"next":     "next",
+	"step":     "step",
+	"stepOut":  "stepOut",
+	"halt":     "halt",
+}
+
+// Debugger controls a program running under a headless Delve inside the
+// debug container. Delve's API only listens on the container's loopback
+// interface, so RPCs are tunnelled through an nc process exec'd next to it.
+type Debugger struct {
+	client    *rpc.Client
+	tunnel    io.Closer
+	mainFile  string
+	closeOnce sync.Once
+}
+
+// EnableDebug makes debug sessions available, executed in debugContainer,
+// which must provide dlv and nc and allow ptrace.
+func (e *Executor) EnableDebug(debugContainer *Container) {
+	e.debugContainer = debugContainer
+}
+
+// Debug starts the program in session under Delve. It returns once the
+// debugger accepts commands; the program is stopped before main runs. The
+// returned function wires the program's input and output to session like Run
+// and blocks until Delve exits.
+func (e *Executor) Debug(ctx context.Context, session *models.ProgramSession) (*Debugger, func() error, error) {
+	c := e.debugContainer
+	if c == nil {
+		return nil, nil, fmt.Errorf("debugger is not available")
+	}
+	// Debug sessions may start together; each builds in its own workspace.
+	dir, remove, err := e.workspaceIn(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := models.RunOptions{Dir: dir}
+	if err := e.copyCode(ctx, c, session.Code, opts); err != nil {
+		remove()
+		return nil, nil, err
+	}
+	mainFile := filepath.Join(dir, "main.go")
+
+	runID := atomic.AddUint64(&e.runCounter, 1)
+	addr := "127.0.0.1:" + strconv.Itoa(40000+int(runID%10000))
+	logPath := filepath.Join(dir, "dlv.log")
+
+	execConfig := container.ExecOptions{
+		Cmd: []string{
+			"dlv", "debug", mainFile,
+			"--headless", "--api-version=2",
+			"--listen=" + addr,
+			"--log-dest=" + logPath,
+			"--output=" + filepath.Join(dir, "debug.bin"),
+		},
+		WorkingDir:   dir,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	execID, err := c.client.ContainerExecCreate(ctx, c.ID, execConfig)
+	if err != nil {
+		remove()
+		return nil, nil, fmt.Errorf("failed to create debug exec: %v", err)
+	}
+
+	response, err := c.client.ContainerExecAttach(ctx, execID.ID, container.ExecStartOptions{})
+	if err != nil {
+		remove()
+		return nil, nil, fmt.Errorf("failed to attach to debug exec: %v", err)
+	}
+
+	if err := e.waitForDelve(ctx, c, execID.ID, logPath, response.Reader); err != nil {
+		response.Close()
+		remove()
+		return nil, nil, err
+	}
+
+	debugger, err := e.connectDelve(ctx, c, addr, mainFile)
+	if err != nil {
+		response.Close()
+		remove()
+		return nil, nil, err
+	}
+
+	wait := func() error {
+		defer remove()
+		defer response.Close()
+		return e.handleExecIO(ctx, response, session, nil)
+	}
+
+	return debugger, wait, nil
+}
+
+// waitForDelve polls Delve's log until its API server is listening. If Delve
+// exits first, e.g. because the program does not build, the error carries
+// what it printed to output.
+func (e *Executor) waitForDelve(ctx context.Context, c *Container, execID string, logPath string, output io.Reader) error {
+	for {
+		logs, _, _, err := e.execOutput(ctx, c, []string{"cat", logPath})
+		if err != nil {
+			return fmt.Errorf("failed to read debugger log: %v", err)
+		}
+		if strings.Contains(logs, "API server listening at") {
+			return nil
+		}
+
+		inspect, err := c.client.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return fmt.Errorf("failed to inspect debug exec: %v", err)
+		}
+		if !inspect.Running {
+			var stderr strings.Builder
+			stdcopy.StdCopy(io.Discard, &stderr, output)
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				return fmt.Errorf("debugger failed to start: %s", message)
+			}
+			return fmt.Errorf("debugger failed to start: %s", strings.TrimSpace(logs))
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for debugger: %v", ctx.Err())
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// connectDelve opens a JSON-RPC client to the Delve server listening on addr
+// inside c, debugging the program in mainFile.
+func (e *Executor) connectDelve(ctx context.Context, c *Container, addr string, mainFile string) (*Debugger, error) {
+	host, port, _ := strings.Cut(addr, ":")
+	execConfig := container.ExecOptions{
+		Cmd:          []string{"nc", host, port},
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	execID, err := c.client.ContainerExecCreate(ctx, c.ID, execConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create debugger tunnel: %v", err)
+	}
+
+	response, err := c.client.ContainerExecAttach(ctx, execID.ID, container.ExecStartOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to debugger tunnel: %v", err)
+	}
+
+	// The exec stream multiplexes stdout and stderr; only stdout carries RPCs.
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(writer, io.Discard, response.Reader)
+		writer.CloseWithError(err)
+	}()
+
+	conn := struct {
+		io.Reader
+		io.Writer
+		io.Closer
+	}{reader, response.Conn, response.Conn}
+
+	return &Debugger{
+		client:   jsonrpc.NewClient(conn),
+		tunnel:   response.Conn,
+		mainFile: mainFile,
+	}, nil
+}
+
+// CreateBreakpoint sets a breakpoint on a line of the submitted program.
+func (d *Debugger) CreateBreakpoint(line int) (models.DebugBreakpoint, error) {
+	var out dlvCreateBreakpointOut
+	in := dlvCreateBreakpointIn{Breakpoint: dlvBreakpoint{File: d.mainFile, Line: line}}
+	if err := d.client.Call("RPCServer.CreateBreakpoint", in, &out); err != nil {
+		return models.DebugBreakpoint{}, fmt.Errorf("failed to create breakpoint: %v", err)
+	}
+	return models.DebugBreakpoint{ID: out.Breakpoint.ID, Line: out.Breakpoint.Line}, nil
+}
+
+func (d *Debugger) ClearBreakpoint(id int) error {
+	var out struct{}
+	if err := d.client.Call("RPCServer.ClearBreakpoint", dlvClearBreakpointIn{Id: id}, &out); err != nil {
+		return fmt.Errorf("failed to clear breakpoint: %v", err)
+	}
+	return nil
+}
+
+// Command runs an execution command and blocks until the program stops again.
+// Once the program has exited the debugger is shut down.
+func (d *Debugger) Command(command string, goroutineID int64) (models.DebugState, error) {
+	name, ok := debugCommands[command]
+	if !ok {
+		return models.DebugState{}, fmt.Errorf("unknown debug command %q", command)
+	}
+
+	var out dlvCommandOut
+	if err := d.client.Call("RPCServer.Command", dlvCommand{Name: name, GoroutineID: goroutineID}, &out); err != nil {
+		return models.DebugState{}, fmt.Errorf("debug command %s failed: %v", command, err)
+	}
+
+	if out.State.Exited {
+		go d.Close()
+	}
+	return d.convertState(out.State), nil
+}
+
+func (d *Debugger) Goroutines() ([]models.DebugGoroutine, error) {
+	var out dlvListGoroutinesOut
+	if err := d.client.Call("RPCServer.ListGoroutines", dlvListGoroutinesIn{Count: 1000}, &out); err != nil {
+		return nil, fmt.Errorf("failed to list goroutines: %v", err)
+	}
+
+	goroutines := make([]models.DebugGoroutine, 0, len(out.Goroutines))
+	for _, g := range out.Goroutines {
+		goroutines = append(goroutines, models.DebugGoroutine{
+			ID:              g.ID,
+			CurrentLocation: d.convertLocation(g.CurrentLoc),
+			UserLocation:    d.convertLocation(g.UserCurrentLoc),
+			StartLocation:   d.convertLocation(g.StartLoc),
+		})
+	}
+	return goroutines, nil
+}
+
+func (d *Debugger) Stack(goroutineID int64) ([]models.DebugFrame, error) {
+	var out dlvStacktraceOut
+	if err := d.client.Call("RPCServer.Stacktrace", dlvStacktraceIn{Id: scopeGoroutine(goroutineID), Depth: 50}, &out); err != nil {
+		return nil, fmt.Errorf("failed to get stack trace: %v", err)
+	}
+
+	frames := make([]models.DebugFrame, 0, len(out.Locations))
+	for i, frame := range out.Locations {
+		frames = append(frames, models.DebugFrame{
+			Index:    i,
+			Location: *d.convertLocation(frame.dlvLocation),
+		})
+	}
+	return frames, nil
+}
+
+// Variables returns the arguments and local variables of a stack frame.
+func (d *Debugger) Variables(goroutineID int64, frame int) ([]models.DebugVariable, error) {
+	in := dlvListVarsIn{
+		Scope: dlvEvalScope{GoroutineID: scopeGoroutine(goroutineID), Frame: frame},
+		Cfg:   dlvLoadConfigDefault,
+	}
+
+	var args dlvListFunctionArgsOut
+	if err := d.client.Call("RPCServer.ListFunctionArgs", in, &args); err != nil {
+		return nil, fmt.Errorf("failed to list arguments: %v", err)
+	}
+	var locals dlvListLocalVarsOut
+	if err := d.client.Call("RPCServer.ListLocalVars", in, &locals); err != nil {
+		return nil, fmt.Errorf("failed to list local variables: %v", err)
+	}
+
+	variables := make([]models.DebugVariable, 0, len(args.Args)+len(locals.Variables))
+	for _, v := range args.Args {
+		variable := convertVariable(v)
+		variable.Argument = true
+		variables = append(variables, variable)
+	}
+	for _, v := range locals.Variables {
+		variables = append(variables, convertVariable(v))
+	}
+	return variables, nil
+}
+
+// Eval evaluates an expression in the scope of a stack frame.
+func (d *Debugger) Eval(goroutineID int64, frame int, expr string) (models.DebugVariable, error) {
+	in := dlvEvalIn{
+		Scope: dlvEvalScope{GoroutineID: scopeGoroutine(goroutineID), Frame: frame},
+		Expr:  expr,
+		Cfg:   &dlvLoadConfigDefault,
+	}
+
+	var out dlvEvalOut
+	if err := d.client.Call("RPCServer.Eval", in, &out); err != nil {
+		return models.DebugVariable{}, fmt.Errorf("failed to evaluate %q: %v", expr, err)
+	}
+	if out.Variable == nil {
+		return models.DebugVariable{}, fmt.Errorf("failed to evaluate %q", expr)
+	}
+	return convertVariable(*out.Variable), nil
+}
+
+// Close kills the debugged program, which makes Delve exit.
+func (d *Debugger) Close() {
+	d.closeOnce.Do(func() {
+		var out struct{}
+		done := make(chan struct{})
+		go func() {
+			d.client.Call("RPCServer.Detach", dlvDetachIn{Kill: true}, &out)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+		d.client.Close()
+		d.tunnel.Close()
+	})
+}
+
+// scopeGoroutine maps the goroutine ID 0 used by clients for "the current
+// goroutine" to Delve's -1.
+func scopeGoroutine(id int64) int64 {
+	if id == 0 {
+		return -1
+	}
+	return id
+}
+
+func (d *Debugger) convertState(state dlvDebuggerState) models.DebugState {
+	result := models.DebugState{
+		Running:    state.Running,
+		Exited:     state.Exited,
+		ExitStatus: state.ExitStatus,
+	}
+	if t := state.CurrentThread; t != nil {
+		result.GoroutineID = t.GoroutineID
+		result.Location = d.convertLocation(dlvLocation{File: t.File, Line: t.Line, Function: t.Function})
+		if t.Breakpoint != nil {
+			result.Breakpoint = t.Breakpoint.ID
+		}
+	}
+	return result
+}
+
+func (d *Debugger) convertLocation(loc dlvLocation) *models.DebugLocation {
+	if loc.File == "" {
+		return nil
+	}
+	result := &models.DebugLocation{
+		File: loc.File,
+		Line: loc.Line,
+		User: loc.File == d.mainFile,
+	}
+	if loc.Function != nil {
+		result.Function = loc.Function.Name
+	}
+	if result.User {
+		result.File = "main.go"
+	}
+	return result
+}
+
+func convertVariable(v dlvVariable) models.DebugVariable {
+	result := models.DebugVariable{
+		Name:  v.Name,
+		Type:  v.Type,
+		Value: v.Value,
+	}
+	if v.Unreadable != "" {
+		result.Value = "unreadable: " + v.Unreadable
+	}
+	for _, child := range v.Children {
+		result.Children = append(result.Children, convertVariable(child))
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package docker
+
+// Wire types of Delve's JSON-RPC API (version 2). Only the fields the
+// playground uses are declared; the rest are ignored when decoding.
+
+type dlvFunction struct {
+	Name string `json:"name"`
+}
+
+type dlvLocation struct {
+	PC       uint64       `json:"pc"`
+	File     string       `json:"file"`
+	Line     int          `json:"line"`
+	Function *dlvFunction `json:"function,omitempty"`
+}
+
+type dlvBreakpoint struct {
+	ID   int    `json:"id"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+type dlvThread struct {
+	ID          int            `json:"id"`
+	File        string         `json:"file"`
+	Line        int            `json:"line"`
+	Function    *dlvFunction   `json:"function,omitempty"`
+	GoroutineID int64          `json:"goroutineID"`
+	Breakpoint  *dlvBreakpoint `json:"breakPoint,omitempty"`
+}
+
+type dlvGoroutine struct {
+	ID             int64       `json:"id"`
+	CurrentLoc     dlvLocation `json:"currentLoc"`
+	UserCurrentLoc dlvLocation `json:"userCurrentLoc"`
+	StartLoc       dlvLocation `json:"startLoc"`
+}
+
+type dlvDebuggerState struct {
+	Running           bool          `json:"Running"`
+	CurrentThread     *dlvThread    `json:"currentThread,omitempty"`
+	SelectedGoroutine *dlvGoroutine `json:"currentGoroutine,omitempty"`
+	Exited            bool          `json:"exited"`
+	ExitStatus        int           `json:"exitStatus"`
+}
+
+type dlvVariable struct {
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Value      string        `json:"value"`
+	Children   []dlvVariable `json:"children"`
+	Unreadable string        `json:"unreadable"`
+}
+
+type dlvStackframe struct {
+	dlvLocation
+	Err string `json:"Err"`
+}
+
+type dlvLoadConfig struct {
+	FollowPointers     bool
+	MaxVariableRecurse int
+	MaxStringLen       int
+	MaxArrayValues     int
+	MaxStructFields    int
+}
+
+type dlvEvalScope struct {
+	GoroutineID int64
+	Frame       int
+}
+
+type dlvCommand struct {
+	Name        string `json:"name"`
+	GoroutineID int64  `json:"goroutineID,omitempty"`
+}
+
+type dlvCreateBreakpointIn struct {
+	Breakpoint dlvBreakpoint
+}
+
+type dlvCreateBreakpointOut struct {
+	Breakpoint dlvBreakpoint
+}
+
+type dlvClearBreakpointIn struct {
+	Id int
+}
+
+type dlvCommandOut struct {
+	State dlvDebuggerState
+}
+
+type dlvListGoroutinesIn struct {
+	Start int
+	Count int
+}
+
+type dlvListGoroutinesOut struct {
+	Goroutines []*dlvGoroutine
+}
+
+type dlvStacktraceIn struct {
+	Id    int64
+	Depth int
+}
+
+type dlvStacktraceOut struct {
+	Locations []dlvStackframe
+}
+
+type dlvListVarsIn struct {
+	Scope dlvEvalScope
+	Cfg   dlvLoadConfig
+}
+
+type dlvListLocalVarsOut struct {
+	Variables []dlvVariable
+}
+
+type dlvListFunctionArgsOut struct {
+	Args []dlvVariable
+}
+
+type dlvEvalIn struct {
+	Scope dlvEvalScope
+	Expr  string
+	Cfg   *dlvLoadConfig
+}
+
+type dlvEvalOut struct {
+	Variable *dlvVariable
+}
+
+type dlvDetachIn struct {
+	Kill bool
+}
+
+// dlvLoadConfigDefault bounds how much of each variable is read from the target.
+var dlvLoadConfigDefault = dlvLoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       256,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
@@ -8,11 +8,14 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"os"
+	"log"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/AlexandruC0909/playground/internal/imports"
 	"github.com/AlexandruC0909/playground/internal/models"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -20,8 +23,15 @@ import (
 )
 
 type Executor struct {
-	container *Container
-	workDir   string
+	container      *Container
+	raceContainer  *Container
+	debugContainer *Container
+	lspContainer   *Container
+	workDir        string
+	runCounter     uint64
+
+	stdlibMu sync.Mutex
+	stdlib   imports.Index
 }
 
 func NewExecutor(container *Container, workDir string) *Executor {
@@ -31,110 +41,292 @@ func NewExecutor(container *Container, workDir string) *Executor {
 	}
 }
 
-func (e *Executor) Compile(ctx context.Context, code string) error {
-	tempDir, err := os.MkdirTemp("", "goplayground")
+// EnableRace makes race detector runs available, executed in raceContainer.
+func (e *Executor) EnableRace(raceContainer *Container) {
+	e.raceContainer = raceContainer
+}
+
+func (e *Executor) containerFor(opts models.RunOptions) (*Container, error) {
+	if opts.Race {
+		if e.raceContainer == nil {
+			return nil, fmt.Errorf("race detector is not available")
+		}
+		return e.raceContainer, nil
+	}
+	return e.container, nil
+}
+
+// Workspace creates a directory for a run with opts to use as its
+// RunOptions.Dir, so that concurrent runs do not overwrite each other's
+// code, and returns it with a function that removes it.
+func (e *Executor) Workspace(ctx context.Context, opts models.RunOptions) (string, func(), error) {
+	c, err := e.containerFor(opts)
 	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %v", err)
+		return "", nil, err
 	}
-	defer os.RemoveAll(tempDir)
+	return e.workspaceIn(ctx, c)
+}
 
-	tempFile := filepath.Join(tempDir, "main.go")
-	if err := os.WriteFile(tempFile, []byte(code), 0600); err != nil {
-		return fmt.Errorf("failed to write code to file: %v", err)
+// workspaceIn creates a workspace in c.
+func (e *Executor) workspaceIn(ctx context.Context, c *Container) (string, func(), error) {
+	runID := atomic.AddUint64(&e.runCounter, 1)
+	dir := fmt.Sprintf("/tmp/work-%d", runID)
+
+	_, stderr, exitCode, err := e.execOutput(ctx, c, []string{"mkdir", "-p", dir})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create workspace: %v", err)
+	}
+	if exitCode != 0 {
+		return "", nil, fmt.Errorf("failed to create workspace: %s", stderr)
 	}
 
-	tar := createTarFromFile(tempFile)
-	if err := e.container.client.CopyToContainer(ctx, e.container.ID, e.workDir, tar, types.CopyToContainerOptions{}); err != nil {
-		return fmt.Errorf("failed to copy code to container: %v", err)
+	remove := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, _, _, err := e.execOutput(ctx, c, []string{"rm", "-rf", dir}); err != nil {
+			log.Printf("Failed to remove workspace: %v\n", err)
+		}
 	}
+	return dir, remove, nil
+}
 
-	execConfig := container.ExecOptions{
-		Cmd:          []string{"go", "build", "-o", "/dev/null", filepath.Join(e.workDir, "main.go")},
-		WorkingDir:   e.workDir,
-		AttachStdout: true,
-		AttachStderr: true,
+// dirFor returns the directory the program of a run with opts lives in.
+func (e *Executor) dirFor(opts models.RunOptions) string {
+	if opts.Dir != "" {
+		return opts.Dir
 	}
+	return e.workDir
+}
 
-	execID, err := e.container.client.ContainerExecCreate(ctx, e.container.ID, execConfig)
+func (e *Executor) Compile(ctx context.Context, code string, opts models.RunOptions) error {
+	c, err := e.containerFor(opts)
 	if err != nil {
-		return fmt.Errorf("failed to create compile exec: %v", err)
+		return err
 	}
 
-	response, err := e.container.client.ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
-	if err != nil {
-		return fmt.Errorf("failed to attach to compile exec: %v", err)
+	if err := e.copyCode(ctx, c, code, opts); err != nil {
+		return err
 	}
-	defer response.Close()
 
-	var stdout, stderr bytes.Buffer
-	if _, err := stdcopy.StdCopy(&stdout, &stderr, response.Reader); err != nil {
-		return fmt.Errorf("failed to read compile output: %v", err)
+	cmd := []string{"go", "build"}
+	if opts.Race {
+		cmd = append(cmd, "-race")
 	}
+	cmd = append(cmd, "-o", "/dev/null")
+	cmd = append(cmd, e.entryFiles(opts)...)
 
-	inspect, err := e.container.client.ContainerExecInspect(ctx, execID.ID)
+	_, stderr, exitCode, err := e.execOutput(ctx, c, cmd)
 	if err != nil {
-		return fmt.Errorf("failed to inspect compile exec: %v", err)
+		return fmt.Errorf("compile: %v", err)
 	}
 
-	if inspect.ExitCode != 0 {
-		return fmt.Errorf("compilation failed: %s", stderr.String())
+	if exitCode != 0 {
+		return fmt.Errorf("compilation failed: %s", stderr)
 	}
 
 	return nil
 }
 
-func (e *Executor) Run(ctx context.Context, session *models.ProgramSession) error {
+func (e *Executor) Run(ctx context.Context, session *models.ProgramSession, opts models.RunOptions) error {
+	c, err := e.containerFor(opts)
+	if err != nil {
+		return err
+	}
+	runID := atomic.AddUint64(&e.runCounter, 1)
+
+	cmd := []string{"go", "run"}
+	var env []string
+	var finishers []func(*models.ProgramOutput)
+
+	if opts.Race {
+		// Reports go to a log file instead of stderr so they can be parsed
+		// once the program exits without ending the output stream early.
+		logPath := fmt.Sprintf("/tmp/race-%d", runID)
+		cmd = append(cmd, "-race")
+		env = append(env, "GORACE=log_path="+logPath+" exitcode=0")
+		finishers = append(finishers, func(output *models.ProgramOutput) {
+			output.Races = e.collectRaces(ctx, c, logPath, session.Code, e.dirFor(opts))
+		})
+	}
+	if opts.Cover {
+		// The coverage runtime requires GOCOVERDIR to exist before the program starts.
+		coverDir := fmt.Sprintf("/tmp/cover-%d", runID)
+		_, stderr, exitCode, err := e.execOutput(ctx, c, []string{"mkdir", "-p", coverDir})
+		if err != nil {
+			return fmt.Errorf("failed to prepare coverage directory: %v", err)
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("failed to prepare coverage directory: %s", stderr)
+		}
+		cmd = append(cmd, "-cover", "-covermode=count")
+		env = append(env, "GOCOVERDIR="+coverDir)
+		finishers = append(finishers, func(output *models.ProgramOutput) {
+			output.Coverage = e.collectCoverage(ctx, c, coverDir)
+		})
+	}
+	if needsWrapper(opts) {
+		outputDir := fmt.Sprintf("/tmp/run-%d", runID)
+		env = append(env, "PLAYGROUND_OUTPUT_DIR="+outputDir)
+		finishers = append(finishers, func(output *models.ProgramOutput) {
+			e.collectOutputs(ctx, c, outputDir, output)
+		})
+	}
+	cmd = append(cmd, e.entryFiles(opts)...)
+
+	finish := func(output *models.ProgramOutput) {
+		for _, f := range finishers {
+			f(output)
+		}
+	}
+
 	execConfig := container.ExecOptions{
-		Cmd:          []string{"go", "run", filepath.Join(e.workDir, "main.go")},
-		WorkingDir:   e.workDir,
+		Cmd:          cmd,
+		Env:          env,
+		WorkingDir:   e.dirFor(opts),
 		AttachStdin:  true,
 		AttachStdout: true,
 		AttachStderr: true,
 		Tty:          false,
 	}
 
-	execID, err := e.container.client.ContainerExecCreate(ctx, e.container.ID, execConfig)
+	execID, err := c.client.ContainerExecCreate(ctx, c.ID, execConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create run exec: %v", err)
 	}
 
-	response, err := e.container.client.ContainerExecAttach(ctx, execID.ID, container.ExecStartOptions{})
+	response, err := c.client.ContainerExecAttach(ctx, execID.ID, container.ExecStartOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to attach to run exec: %v", err)
 	}
 	defer response.Close()
 
-	return e.handleExecIO(ctx, response, session)
+	return e.handleExecIO(ctx, response, session, finish)
 }
 
-func (e *Executor) handleExecIO(ctx context.Context, response types.HijackedResponse, session *models.ProgramSession) error {
+// copyCode writes the program's source files into the work directory of c.
+func (e *Executor) copyCode(ctx context.Context, c *Container, code string, opts models.RunOptions) error {
+	files, err := sourceFiles(code, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := c.client.CopyToContainer(ctx, c.ID, e.dirFor(opts), createTarFromFiles(files), types.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("failed to copy code to container: %v", err)
+	}
+	return nil
+}
+
+// sourceFiles returns the files, keyed by name, that make up the program for
+// the given options.
+func sourceFiles(code string, opts models.RunOptions) (map[string][]byte, error) {
+	if !needsWrapper(opts) {
+		return map[string][]byte{"main.go": []byte(code)}, nil
+	}
+
+	renamed, err := renameMain(code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare program: %v", err)
+	}
+	return map[string][]byte{
+		"main.go":   []byte(renamed),
+		wrapperFile: []byte(wrapperSource(opts)),
+	}, nil
+}
+
+// entryFiles returns the paths of the files passed to go build and go run.
+func (e *Executor) entryFiles(opts models.RunOptions) []string {
+	files := []string{filepath.Join(e.dirFor(opts), "main.go")}
+	if needsWrapper(opts) {
+		files = append(files, filepath.Join(e.dirFor(opts), wrapperFile))
+	}
+	return files
+}
+
+// execOutput runs cmd in c to completion and returns its output and exit code.
+func (e *Executor) execOutput(ctx context.Context, c *Container, cmd []string) (string, string, int, error) {
+	execConfig := container.ExecOptions{
+		Cmd:          cmd,
+		WorkingDir:   e.workDir,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	execID, err := c.client.ContainerExecCreate(ctx, c.ID, execConfig)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to create exec: %v", err)
+	}
+
+	response, err := c.client.ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to attach to exec: %v", err)
+	}
+	defer response.Close()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, response.Reader); err != nil {
+		return "", "", 0, fmt.Errorf("failed to read exec output: %v", err)
+	}
+
+	inspect, err := c.client.ContainerExecInspect(ctx, execID.ID)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to inspect exec: %v", err)
+	}
+
+	return stdout.String(), stderr.String(), inspect.ExitCode, nil
+}
+
+// handleExecIO streams the program's output to the session and forwards its
+// input. finish, if set, is called once the program has exited to attach any
+// collected results to the final output message. Once ctx is done, output
+// is no longer waited for, so that a client that stopped reading cannot keep
+// the run going.
+func (e *Executor) handleExecIO(ctx context.Context, response types.HijackedResponse, session *models.ProgramSession, finish func(*models.ProgramOutput)) error {
 	reader := bufio.NewReader(response.Reader)
 	outputDone := make(chan struct{})
 
-	go e.processOutput(reader, session, outputDone)
+	go e.processOutput(ctx, reader, session, outputDone, finish)
 
-	return e.processInput(response, session, outputDone)
+	return e.processInput(ctx, response, session, outputDone)
 }
 
-func (e *Executor) processOutput(reader *bufio.Reader, session *models.ProgramSession, outputDone chan struct{}) {
+// processOutput reads the program's output and sends it to the session,
+// reporting each output to the session's Observer first.
+func (e *Executor) processOutput(ctx context.Context, reader *bufio.Reader, session *models.ProgramSession, outputDone chan struct{}, finish func(*models.ProgramOutput)) {
 	defer close(outputDone)
 	defer close(session.OutputChan)
 
+	send := func(output models.ProgramOutput) bool {
+		if session.Observer != nil {
+			session.Observer(output)
+		}
+		select {
+		case session.OutputChan <- output:
+			return true
+		case <-session.Done:
+		case <-ctx.Done():
+		}
+		return false
+	}
+
 	for {
 		header := make([]byte, 8)
 		_, err := reader.Read(header)
 		if err != nil {
 			if err != io.EOF {
-				session.OutputChan <- models.ProgramOutput{
+				send(models.ProgramOutput{
 					Error:           fmt.Sprintf("error reading output: %v", err),
 					Done:            true,
 					WaitingForInput: false,
-				}
+				})
 			} else {
-				session.OutputChan <- models.ProgramOutput{
+				output := models.ProgramOutput{
 					Done:            true,
 					WaitingForInput: false,
 				}
+				if finish != nil {
+					finish(&output)
+				}
+				send(output)
 			}
 			return
 		}
@@ -144,11 +336,11 @@ func (e *Executor) processOutput(reader *bufio.Reader, session *models.ProgramSe
 
 		content := make([]byte, size)
 		if _, err = io.ReadFull(reader, content); err != nil {
-			session.OutputChan <- models.ProgramOutput{
+			send(models.ProgramOutput{
 				Error:           fmt.Sprintf("error reading content: %v", err),
 				Done:            true,
 				WaitingForInput: false,
-			}
+			})
 			return
 		}
 
@@ -165,15 +357,13 @@ func (e *Executor) processOutput(reader *bufio.Reader, session *models.ProgramSe
 			output.WaitingForInput = false
 		}
 
-		select {
-		case <-session.Done:
+		if !send(output) {
 			return
-		case session.OutputChan <- output:
 		}
 	}
 }
 
-func (e *Executor) processInput(response types.HijackedResponse, session *models.ProgramSession, outputDone chan struct{}) error {
+func (e *Executor) processInput(ctx context.Context, response types.HijackedResponse, session *models.ProgramSession, outputDone chan struct{}) error {
 	for {
 		select {
 		case input, ok := <-session.InputChan:
@@ -187,43 +377,68 @@ func (e *Executor) processInput(response types.HijackedResponse, session *models
 			return nil
 		case <-outputDone:
 			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 }
 
 // Helper functions
-func createTarFromFile(filePath string) io.Reader {
+func createTarFromFiles(files map[string][]byte) io.Reader {
 	var buf bytes.Buffer
 	tw := tar.NewWriter(&buf)
 	defer tw.Close()
 
-	file, err := os.Open(filePath)
-	if err != nil {
-		return &buf
-	}
-	defer file.Close()
+	for name, content := range files {
+		header := &tar.Header{
+			Name:    name,
+			Size:    int64(len(content)),
+			Mode:    0600,
+			ModTime: time.Now(),
+		}
 
-	info, err := file.Stat()
-	if err != nil {
-		return &buf
-	}
+		if err := tw.WriteHeader(header); err != nil {
+			return &buf
+		}
 
-	header := &tar.Header{
-		Name:    "main.go",
-		Size:    info.Size(),
-		Mode:    0600,
-		ModTime: time.Now(),
+		if _, err := tw.Write(content); err != nil {
+			return &buf
+		}
 	}
 
-	if err := tw.WriteHeader(header); err != nil {
-		return &buf
+	return &buf
+}
+
+// readContainerFiles copies the regular files of dir out of c, keyed by base name.
+func readContainerFiles(ctx context.Context, c *Container, dir string) (map[string][]byte, error) {
+	reader, _, err := c.client.CopyFromContainer(ctx, c.ID, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy from container: %v", err)
 	}
+	defer reader.Close()
 
-	if _, err := io.Copy(tw, file); err != nil {
-		return &buf
+	files := make(map[string][]byte)
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", header.Name, err)
+		}
+		files[filepath.Base(header.Name)] = content
 	}
 
-	return &buf
+	return files, nil
 }
 
 func isWaitingForInput(output string, detectedOps []models.InputOperation) bool {
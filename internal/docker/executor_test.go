@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// execStream multiplexes chunks as Docker does for attached execs. Chunks
+// starting with "!" go to stderr.
+func execStream(chunks ...string) *bufio.Reader {
+	var buf bytes.Buffer
+	for _, chunk := range chunks {
+		header := make([]byte, 8)
+		header[0] = 1
+		if chunk[0] == '!' {
+			header[0] = 2
+			chunk = chunk[1:]
+		}
+		binary.BigEndian.PutUint32(header[4:], uint32(len(chunk)))
+		buf.Write(header)
+		buf.WriteString(chunk)
+	}
+	return bufio.NewReader(&buf)
+}
+
+func TestProcessOutput(t *testing.T) {
+	session := models.NewSession()
+	var observed []models.ProgramOutput
+	session.Observer = func(output models.ProgramOutput) {
+		observed = append(observed, output)
+	}
+	finish := func(output *models.ProgramOutput) {
+		output.Coverage = &models.CoverageResult{Mode: "set"}
+	}
+
+	outputDone := make(chan struct{})
+	go (&Executor{}).processOutput(context.Background(), execStream("hello\n", "!oops\n"), session, outputDone, finish)
+
+	var streamed []models.ProgramOutput
+	for output := range session.OutputChan {
+		streamed = append(streamed, output)
+	}
+	<-outputDone
+
+	want := []models.ProgramOutput{
+		{Output: "hello\n"},
+		{Error: "oops\n"},
+		{Done: true, Coverage: &models.CoverageResult{Mode: "set"}},
+	}
+	if !reflect.DeepEqual(streamed, want) {
+		t.Errorf("streamed %+v, want %+v", streamed, want)
+	}
+	if !reflect.DeepEqual(observed, want) {
+		t.Errorf("observed %+v, want %+v", observed, want)
+	}
+}
+
+// Outputs are observed as they are produced even if no client reads them,
+// and the run is not held up once its context is done.
+func TestProcessOutputWithoutReader(t *testing.T) {
+	session := models.NewSession()
+	observed := make(chan models.ProgramOutput, 10)
+	session.Observer = func(output models.ProgramOutput) {
+		observed <- output
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	outputDone := make(chan struct{})
+	go (&Executor{}).processOutput(ctx, execStream("hello\n", "bye\n"), session, outputDone, nil)
+
+	select {
+	case output := <-observed:
+		if output.Output != "hello\n" {
+			t.Errorf("observed %+v, want the first output", output)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no output observed")
+	}
+
+	cancel()
+	select {
+	case <-outputDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("processOutput did not return once its context was done")
+	}
+}
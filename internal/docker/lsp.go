@@ -0,0 +1,160 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// LanguageServer is a gopls process serving one editor connection from its
+// own workspace directory inside the tools container. Messages are exchanged
+// without the LSP base protocol headers, and document URIs use
+// config.LSPRootURI in place of the real workspace directory.
+type LanguageServer struct {
+	executor  *Executor
+	container *Container
+	dir       string
+	stdin     io.WriteCloser
+	stdout    *bufio.Reader
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+}
+
+// EnableLanguageServer makes gopls sessions available, executed in
+// toolsContainer, which must provide gopls.
+func (e *Executor) EnableLanguageServer(toolsContainer *Container) {
+	e.lspContainer = toolsContainer
+}
+
+// LanguageServer starts gopls in a fresh module workspace holding an empty
+// main package, which editors fill with textDocument/didOpen.
+func (e *Executor) LanguageServer(ctx context.Context) (*LanguageServer, error) {
+	c := e.lspContainer
+	if c == nil {
+		return nil, fmt.Errorf("language server is not available")
+	}
+
+	runID := atomic.AddUint64(&e.runCounter, 1)
+	dir := fmt.Sprintf("/tmp/lsp-%d", runID)
+
+	_, stderr, exitCode, err := e.execOutput(ctx, c, []string{"mkdir", "-p", dir})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %v", err)
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("failed to create workspace: %s", stderr)
+	}
+
+	files := map[string][]byte{
+		"go.mod":  []byte("module playground\n\ngo 1.22\n"),
+		"main.go": []byte("package main\n"),
+	}
+	if err := c.client.CopyToContainer(ctx, c.ID, dir, createTarFromFiles(files), types.CopyToContainerOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to copy workspace to container: %v", err)
+	}
+
+	execConfig := container.ExecOptions{
+		Cmd:          []string{"gopls", "serve"},
+		WorkingDir:   dir,
+		Env:          []string{"GOTOOLCHAIN=local", "GOFLAGS=-mod=mod", "GOPROXY=off"},
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+
+	execID, err := c.client.ContainerExecCreate(ctx, c.ID, execConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create language server exec: %v", err)
+	}
+
+	response, err := c.client.ContainerExecAttach(ctx, execID.ID, container.ExecStartOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to language server exec: %v", err)
+	}
+
+	// gopls logs to stderr; only stdout carries protocol messages.
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(writer, io.Discard, response.Reader)
+		writer.CloseWithError(err)
+	}()
+
+	return &LanguageServer{
+		executor:  e,
+		container: c,
+		dir:       dir,
+		stdin:     response.Conn,
+		stdout:    bufio.NewReader(reader),
+	}, nil
+}
+
+// ReadMessage returns the next message sent by gopls.
+func (s *LanguageServer) ReadMessage() ([]byte, error) {
+	header, err := textproto.NewReader(s.stdout).ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message header: %v", err)
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid message length %q", header.Get("Content-Length"))
+	}
+	if length > config.MaxLSPMessageSize {
+		return nil, fmt.Errorf("message too large: %d bytes", length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.stdout, body); err != nil {
+		return nil, fmt.Errorf("failed to read message: %v", err)
+	}
+
+	return bytes.ReplaceAll(body, []byte(s.workspaceURI()), []byte(config.LSPRootURI)), nil
+}
+
+// WriteMessage sends a message to gopls.
+func (s *LanguageServer) WriteMessage(message []byte) error {
+	message = bytes.ReplaceAll(message, []byte(config.LSPRootURI), []byte(s.workspaceURI()))
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if _, err := fmt.Fprintf(s.stdin, "Content-Length: %d\r\n\r\n", len(message)); err != nil {
+		return fmt.Errorf("failed to write message header: %v", err)
+	}
+	if _, err := s.stdin.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %v", err)
+	}
+	return nil
+}
+
+// Close stops gopls and removes its workspace.
+func (s *LanguageServer) Close() error {
+	s.closeOnce.Do(func() {
+		// gopls exits once its stdin is closed.
+		s.stdin.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, _, _, err := s.executor.execOutput(ctx, s.container, []string{"rm", "-rf", s.dir}); err != nil {
+			log.Printf("Failed to remove language server workspace: %v\n", err)
+		}
+	})
+	return nil
+}
+
+func (s *LanguageServer) workspaceURI() string {
+	return "file://" + s.dir
+}
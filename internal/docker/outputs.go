@@ -0,0 +1,59 @@
+package docker
+
+import (
+	"context"
+	"log"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/profiling"
+)
+
+// collectOutputs reads the files written to dir by the program wrapper,
+// removes them from the container and attaches their processed form to output.
+func (e *Executor) collectOutputs(ctx context.Context, c *Container, dir string, output *models.ProgramOutput) {
+	defer e.execOutput(ctx, c, []string{"rm", "-rf", dir})
+
+	files, err := readContainerFiles(ctx, c, dir)
+	if err != nil {
+		log.Printf("Failed to collect program outputs: %v\n", err)
+		return
+	}
+
+	_, hasCPU := files["cpu.pprof"]
+	_, hasHeap := files["heap.pprof"]
+	if hasCPU || hasHeap {
+		output.Profile = &models.ProfileResult{}
+	}
+	if data, ok := files["cpu.pprof"]; ok {
+		output.Profile.CPU = summarizeProfile(data, "")
+	}
+	if data, ok := files["heap.pprof"]; ok {
+		output.Profile.Heap = summarizeProfile(data, "alloc_space")
+	}
+
+	if data, ok := files["trace.out"]; ok {
+		if output.Trace, err = profiling.Timeline(data); err != nil {
+			log.Printf("Failed to process execution trace: %v\n", err)
+		} else if len(data) > config.MaxTraceSize {
+			// Keep the timeline but don't ship oversized raw traces.
+			output.Trace.Trace = nil
+		}
+	}
+}
+
+// summarizeProfile summarizes the profile data for sampleType, leaving out
+// the raw profile if it is larger than config.MaxProfileSize. It returns nil
+// if data is not a valid profile.
+func summarizeProfile(data []byte, sampleType string) *models.ProfileData {
+	summary, err := profiling.Summarize(data, sampleType, config.ProfileTopN)
+	if err != nil {
+		log.Printf("Failed to summarize profile: %v\n", err)
+		return nil
+	}
+	if len(data) > config.MaxProfileSize {
+		summary.Pprof = nil
+		summary.PprofOmitted = true
+	}
+	return summary
+}
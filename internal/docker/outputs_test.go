@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/google/pprof/profile"
+)
+
+// testProfile returns a CPU profile with one sample in a function named name.
+func testProfile(t *testing.T, name string) []byte {
+	t.Helper()
+	function := &profile.Function{ID: 1, Name: name}
+	location := &profile.Location{ID: 1, Line: []profile.Line{{Function: function}}}
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "cpu", Unit: "nanoseconds"}},
+		Sample:     []*profile.Sample{{Location: []*profile.Location{location}, Value: []int64{10}}},
+		Location:   []*profile.Location{location},
+		Function:   []*profile.Function{function},
+	}
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSummarizeProfile(t *testing.T) {
+	// Random names do not compress, so this one makes the profile too large.
+	random := make([]byte, config.MaxProfileSize)
+	rand.Read(random)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		omitted bool
+	}{
+		{"small", testProfile(t, "main.main"), false},
+		{"too large", testProfile(t, hex.EncodeToString(random)), true},
+	}
+	for _, test := range tests {
+		summary := summarizeProfile(test.data, "")
+		if summary == nil || summary.Total != 10 {
+			t.Fatalf("%s: summary = %+v, want a total of 10", test.name, summary)
+		}
+		if summary.PprofOmitted != test.omitted || (len(summary.Pprof) == 0) != test.omitted {
+			t.Errorf("%s: %d bytes of pprof, omitted %v; want omitted %v", test.name, len(summary.Pprof), summary.PprofOmitted, test.omitted)
+		}
+	}
+
+	if summary := summarizeProfile([]byte("not a profile"), ""); summary != nil {
+		t.Errorf("summary of invalid data = %+v, want nil", summary)
+	}
+}
@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+var (
+	raceAccessRe    = regexp.MustCompile(`^(Previous )?([A-Za-z ]+?) at (0x[0-9a-f]+) by (main goroutine|goroutine \d+):$`)
+	raceGoroutineRe = regexp.MustCompile(`^Goroutine (\d+) \((\w+)\) created at:$`)
+	stackFileRe     = regexp.MustCompile(`^\s+(\S+\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// collectRaces reads and removes the race detector logs written under
+// logPath, returning the parsed reports for code, built in dir.
+func (e *Executor) collectRaces(ctx context.Context, c *Container, logPath string, code string, dir string) []models.RaceReport {
+	script := "cat " + logPath + ".* 2>/dev/null; rm -f " + logPath + ".*"
+	stdout, _, _, err := e.execOutput(ctx, c, []string{"sh", "-c", script})
+	if err != nil {
+		log.Printf("Failed to collect race reports: %v\n", err)
+		return nil
+	}
+	return parseRaceReports(stdout, code, filepath.Join(dir, "main.go"))
+}
+
+// parseRaceReports turns the text output of the race detector into structured
+// reports. Frames located in mainFile are annotated with their source line.
+func parseRaceReports(output string, code string, mainFile string) []models.RaceReport {
+	codeLines := strings.Split(code, "\n")
+	var reports []models.RaceReport
+
+	for _, block := range strings.Split(output, "==================") {
+		if !strings.Contains(block, "WARNING: DATA RACE") {
+			continue
+		}
+
+		var report models.RaceReport
+		// stack points at the frame list currently being filled in.
+		var stack *[]models.StackFrame
+
+		for _, line := range strings.Split(block, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+
+			if m := raceAccessRe.FindStringSubmatch(trimmed); m != nil {
+				report.Accesses = append(report.Accesses, models.RaceAccess{
+					Kind:      strings.ToLower(m[2]),
+					Address:   m[3],
+					Goroutine: strings.TrimPrefix(strings.TrimSuffix(m[4], " goroutine"), "goroutine "),
+					Previous:  m[1] != "",
+				})
+				stack = &report.Accesses[len(report.Accesses)-1].Stack
+				continue
+			}
+
+			if m := raceGoroutineRe.FindStringSubmatch(trimmed); m != nil {
+				report.Goroutines = append(report.Goroutines, models.RaceGoroutine{
+					ID:    m[1],
+					State: m[2],
+				})
+				stack = &report.Goroutines[len(report.Goroutines)-1].CreatedAt
+				continue
+			}
+
+			if stack == nil {
+				continue
+			}
+
+			if m := stackFileRe.FindStringSubmatch(line); m != nil && len(*stack) > 0 {
+				frame := &(*stack)[len(*stack)-1]
+				frame.File = m[1]
+				frame.Line, _ = strconv.Atoi(m[2])
+				if frame.File == mainFile && frame.Line > 0 && frame.Line <= len(codeLines) {
+					frame.Source = strings.TrimSpace(codeLines[frame.Line-1])
+				}
+				continue
+			}
+
+			if strings.HasSuffix(trimmed, ")") && strings.HasPrefix(line, "  ") {
+				*stack = append(*stack, models.StackFrame{Function: funcName(trimmed)})
+				continue
+			}
+
+			// Any other header (mutex creation sites, etc.) ends the current stack.
+			stack = nil
+		}
+
+		if len(report.Accesses) > 0 {
+			reports = append(reports, report)
+		}
+	}
+
+	return reports
+}
+
+// funcName strips the argument list from a stack trace function line.
+func funcName(line string) string {
+	if i := strings.LastIndex(line, "("); i > 0 {
+		return line[:i]
+	}
+	return line
+}
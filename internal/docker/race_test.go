@@ -0,0 +1,124 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+const racyProgram = `package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+func main() {
+	counter := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter++
+		}()
+	}
+	wg.Wait()
+	fmt.Println(counter)
+}
+`
+
+// racyLog is the log the race detector writes for racyProgram.
+const racyLog = `==================
+WARNING: DATA RACE
+Read at 0x00c000018188 by goroutine 8:
+  main.main.func1()
+      /code/main.go:15 +0x7b
+
+Previous write at 0x00c000018188 by goroutine 9:
+  main.main.func1()
+      /code/main.go:15 +0x8d
+
+Goroutine 8 (running) created at:
+  main.main()
+      /code/main.go:13 +0x78
+
+Goroutine 9 (finished) created at:
+  main.main()
+      /code/main.go:13 +0x78
+==================
+Found 1 data race(s)
+`
+
+func TestParseRaceReports(t *testing.T) {
+	increment := models.StackFrame{Function: "main.main.func1", File: "/code/main.go", Line: 15, Source: "counter++"}
+	spawn := models.StackFrame{Function: "main.main", File: "/code/main.go", Line: 13, Source: "go func() {"}
+	racy := []models.RaceReport{{
+		Accesses: []models.RaceAccess{
+			{Kind: "read", Address: "0x00c000018188", Goroutine: "8", Stack: []models.StackFrame{increment}},
+			{Kind: "write", Address: "0x00c000018188", Goroutine: "9", Previous: true, Stack: []models.StackFrame{increment}},
+		},
+		Goroutines: []models.RaceGoroutine{
+			{ID: "8", State: "running", CreatedAt: []models.StackFrame{spawn}},
+			{ID: "9", State: "finished", CreatedAt: []models.StackFrame{spawn}},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		output   string
+		mainFile string
+		want     []models.RaceReport
+	}{
+		{"no races", "", "/code/main.go", nil},
+		{"go func counter", racyLog, "/code/main.go", racy},
+		{
+			// Frames outside the program's main.go are not annotated.
+			"other workspace",
+			racyLog,
+			"/tmp/work-1/main.go",
+			func() []models.RaceReport {
+				bare := func(f models.StackFrame) []models.StackFrame {
+					f.Source = ""
+					return []models.StackFrame{f}
+				}
+				return []models.RaceReport{{
+					Accesses: []models.RaceAccess{
+						{Kind: "read", Address: "0x00c000018188", Goroutine: "8", Stack: bare(increment)},
+						{Kind: "write", Address: "0x00c000018188", Goroutine: "9", Previous: true, Stack: bare(increment)},
+					},
+					Goroutines: []models.RaceGoroutine{
+						{ID: "8", State: "running", CreatedAt: bare(spawn)},
+						{ID: "9", State: "finished", CreatedAt: bare(spawn)},
+					},
+				}}
+			}(),
+		},
+		{
+			"main goroutine",
+			"==================\nWARNING: DATA RACE\nWrite at 0x01 by main goroutine:\n  main.main()\n      /code/main.go:9 +0x1\n==================\n",
+			"/code/main.go",
+			[]models.RaceReport{{Accesses: []models.RaceAccess{{
+				Kind: "write", Address: "0x01", Goroutine: "main",
+				Stack: []models.StackFrame{{Function: "main.main", File: "/code/main.go", Line: 9, Source: "counter := 0"}},
+			}}}},
+		},
+		{
+			// A line past the end of the program is not annotated.
+			"line out of range",
+			"==================\nWARNING: DATA RACE\nRead at 0x01 by goroutine 2:\n  main.f()\n      /code/main.go:99 +0x1\n==================\n",
+			"/code/main.go",
+			[]models.RaceReport{{Accesses: []models.RaceAccess{{
+				Kind: "read", Address: "0x01", Goroutine: "2",
+				Stack: []models.StackFrame{{Function: "main.f", File: "/code/main.go", Line: 99}},
+			}}}},
+		},
+	}
+	for _, test := range tests {
+		got := parseRaceReports(test.output, racyProgram, test.mainFile)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/AlexandruC0909/playground/internal/imports"
+	"github.com/docker/docker/api/types"
+)
+
+const stdlibIndexDir = "/tmp/stdlib-index"
+
+// stdlibIndexSource lists the exported top-level names of every public
+// standard library package of the toolchain it runs with, as JSON matching
+// imports.Index.
+const stdlibIndexSource = `package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type pkg struct {
+	ImportPath string
+	Name       string
+	Dir        string
+	GoFiles    []string
+}
+
+type entry struct {
+	Name    string   ` + "`json:\"name\"`" + `
+	Exports []string ` + "`json:\"exports\"`" + `
+}
+
+func main() {
+	out, err := exec.Command("go", "list", "-json", "std").Output()
+	if err != nil {
+		os.Stderr.WriteString(err.Error())
+		os.Exit(1)
+	}
+
+	index := make(map[string]entry)
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var p pkg
+		if err := dec.Decode(&p); err != nil {
+			os.Stderr.WriteString(err.Error())
+			os.Exit(1)
+		}
+		if p.Name == "main" || strings.Contains(p.ImportPath, "internal") || strings.HasPrefix(p.ImportPath, "vendor/") {
+			continue
+		}
+
+		e := entry{Name: p.Name}
+		fset := token.NewFileSet()
+		for _, name := range p.GoFiles {
+			f, err := parser.ParseFile(fset, filepath.Join(p.Dir, name), nil, parser.SkipObjectResolution)
+			if err != nil {
+				continue
+			}
+			for _, decl := range f.Decls {
+				switch d := decl.(type) {
+				case *ast.FuncDecl:
+					if d.Recv == nil && d.Name.IsExported() {
+						e.Exports = append(e.Exports, d.Name.Name)
+					}
+				case *ast.GenDecl:
+					for _, spec := range d.Specs {
+						switch s := spec.(type) {
+						case *ast.TypeSpec:
+							if s.Name.IsExported() {
+								e.Exports = append(e.Exports, s.Name.Name)
+							}
+						case *ast.ValueSpec:
+							for _, n := range s.Names {
+								if n.IsExported() {
+									e.Exports = append(e.Exports, n.Name)
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		index[p.ImportPath] = e
+	}
+
+	json.NewEncoder(os.Stdout).Encode(index)
+}
+`
+
+// StdlibIndex returns the standard library of the sandbox toolchain for import
+// resolution. It is built on first use and cached; failures are not cached.
+func (e *Executor) StdlibIndex(ctx context.Context) (imports.Index, error) {
+	e.stdlibMu.Lock()
+	defer e.stdlibMu.Unlock()
+
+	if e.stdlib != nil {
+		return e.stdlib, nil
+	}
+
+	c := e.container
+	_, stderr, exitCode, err := e.execOutput(ctx, c, []string{"mkdir", "-p", stdlibIndexDir})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdlib index directory: %v", err)
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("failed to create stdlib index directory: %s", stderr)
+	}
+
+	files := map[string][]byte{"main.go": []byte(stdlibIndexSource)}
+	if err := c.client.CopyToContainer(ctx, c.ID, stdlibIndexDir, createTarFromFiles(files), types.CopyToContainerOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to copy stdlib indexer to container: %v", err)
+	}
+
+	stdout, stderr, exitCode, err := e.execOutput(ctx, c, []string{"go", "run", stdlibIndexDir + "/main.go"})
+	if err != nil {
+		return nil, fmt.Errorf("stdlib index: %v", err)
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("failed to index stdlib: %s", stderr)
+	}
+
+	var index imports.Index
+	if err := json.Unmarshal([]byte(stdout), &index); err != nil {
+		return nil, fmt.Errorf("failed to decode stdlib index: %v", err)
+	}
+
+	e.stdlib = index
+	return index, nil
+}
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/docker/docker/api/types"
+)
+
+// Test runs go test -json over code, as main.go, together with the test
+// files given by name, in a directory of its own. It returns the JSON event
+// stream and the standard error of go test, which holds build errors. A
+// failing test is not an error.
+func (e *Executor) Test(ctx context.Context, code string, tests map[string][]byte) (string, string, error) {
+	c := e.container
+	dir, remove, err := e.Workspace(ctx, models.RunOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	defer remove()
+
+	files := map[string][]byte{"main.go": []byte(code)}
+	for name, content := range tests {
+		files[name] = content
+	}
+	if err := c.client.CopyToContainer(ctx, c.ID, dir, createTarFromFiles(files), types.CopyToContainerOptions{}); err != nil {
+		return "", "", fmt.Errorf("failed to copy tests to container: %v", err)
+	}
+
+	cmd := []string{"go", "test", "-json", "-count=1", "-timeout", strconv.Itoa(config.TestTimeoutSeconds) + "s"}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd = append(cmd, path.Join(dir, name))
+	}
+
+	stdout, stderr, _, err := e.execOutput(ctx, c, cmd)
+	if err != nil {
+		return "", "", fmt.Errorf("test: %v", err)
+	}
+	return stdout, stderr, nil
+}
@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// wrappedMainName is what the submitted program's main function is renamed to
+// when a wrapper file provides its own main.
+const wrappedMainName = "playgroundMain"
+
+const wrapperFile = "playground_wrapper.go"
+
+// needsWrapper reports whether opts require the program's main to be wrapped.
+func needsWrapper(opts models.RunOptions) bool {
+	return opts.Profile || opts.Trace
+}
+
+// wrapperSource returns a main function that records the diagnostics
+// requested by opts into $PLAYGROUND_OUTPUT_DIR around a call to the
+// submitted program's renamed main.
+func wrapperSource(opts models.RunOptions) string {
+	imports := []string{"os", "path/filepath"}
+	var start, stop []string
+
+	if opts.Trace {
+		imports = append(imports, "runtime/trace")
+		start = append(start, `
+	if f, err := os.Create(filepath.Join(dir, "trace.out")); err == nil {
+		trace.Start(f)
+		defer f.Close()
+		defer trace.Stop()
+	}`)
+	}
+	if opts.Profile {
+		imports = append(imports, "runtime", "runtime/pprof")
+		start = append(start, `
+	if f, err := os.Create(filepath.Join(dir, "cpu.pprof")); err == nil {
+		pprof.StartCPUProfile(f)
+		defer f.Close()
+		defer pprof.StopCPUProfile()
+	}`)
+		stop = append(stop, `
+	if f, err := os.Create(filepath.Join(dir, "heap.pprof")); err == nil {
+		runtime.GC()
+		pprof.WriteHeapProfile(f)
+		f.Close()
+	}`)
+	}
+
+	var b strings.Builder
+	b.WriteString("package main\n\nimport (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "\t%q\n", imp)
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+	b.WriteString("\tdir := os.Getenv(\"PLAYGROUND_OUTPUT_DIR\")\n\tos.MkdirAll(dir, 0700)\n")
+	b.WriteString(strings.Join(start, "\n"))
+	b.WriteString("\n\n\t" + wrappedMainName + "()\n")
+	b.WriteString(strings.Join(stop, "\n"))
+	b.WriteString("\n}\n")
+	return b.String()
+}
+
+// renameMain renames the main function of code to wrappedMainName. The edit is
+// done in place so line numbers in profiles still match the submitted code.
+func renameMain(code string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, 0)
+	if err != nil {
+		return "", err
+	}
+
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			offset := fset.Position(fn.Name.Pos()).Offset
+			return code[:offset] + wrappedMainName + code[offset+len("main"):], nil
+		}
+	}
+
+	return "", fmt.Errorf("function main is undeclared in the main package")
+}
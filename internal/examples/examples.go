@@ -0,0 +1,185 @@
+package examples
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// defaultCategory holds examples that do not name a category.
+const defaultCategory = "Other"
+
+// Catalog holds the bundled examples. The zero value is an empty catalog.
+type Catalog struct {
+	examples []models.Example
+	byName   map[string]int
+}
+
+// Load reads every .go file in dir as an example named after the file.
+//
+// The files are standalone programs, so each starts with a //go:build ignore
+// constraint that keeps them out of the module's packages. It is followed by
+// a comment block of annotations, like
+//
+//	// Title: Name and color
+//	// Category: Interactive
+//	// Order: 6
+//	// Description: Reads answers from standard input.
+//	// Input:
+//	// Gopher
+//	// Output:
+//	// What's your name?
+//
+// Input may span several lines, up to the next annotation. Output must come
+// last and runs to the end of the block, like the output comment of a Go
+// testable example. Both the constraint and the annotations are removed from
+// the example's code.
+func Load(dir string) (*Catalog, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list examples: %v", err)
+	}
+
+	c := &Catalog{byName: make(map[string]int)}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read example: %v", err)
+		}
+		if len(content) > config.MaxCodeSize {
+			return nil, fmt.Errorf("example %s is too large: %d bytes", path, len(content))
+		}
+
+		example, err := parse(strings.TrimSuffix(filepath.Base(path), ".go"), string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid example %s: %v", path, err)
+		}
+		c.examples = append(c.examples, example)
+	}
+
+	// Examples without an order come last.
+	rank := func(e models.Example) int {
+		if e.Order == 0 {
+			return math.MaxInt
+		}
+		return e.Order
+	}
+	sort.SliceStable(c.examples, func(i, j int) bool {
+		if rank(c.examples[i]) != rank(c.examples[j]) {
+			return rank(c.examples[i]) < rank(c.examples[j])
+		}
+		return c.examples[i].Name < c.examples[j].Name
+	})
+	for i, example := range c.examples {
+		c.byName[example.Name] = i
+	}
+	return c, nil
+}
+
+// Get returns the example called name.
+func (c *Catalog) Get(name string) (models.Example, bool) {
+	i, ok := c.byName[name]
+	if !ok {
+		return models.Example{}, false
+	}
+	return c.examples[i], true
+}
+
+// All returns every example in menu order.
+func (c *Catalog) All() []models.Example {
+	return c.examples
+}
+
+// Categories groups the examples by category, in menu order. A category
+// comes where its first example does.
+func (c *Catalog) Categories() []models.ExampleCategory {
+	var categories []models.ExampleCategory
+	index := make(map[string]int)
+	for _, example := range c.examples {
+		i, ok := index[example.Category]
+		if !ok {
+			i = len(categories)
+			index[example.Category] = i
+			categories = append(categories, models.ExampleCategory{Name: example.Category})
+		}
+		categories[i].Examples = append(categories[i].Examples, example)
+	}
+	return categories
+}
+
+// parse splits the source of an example into its annotations and code.
+func parse(name, source string) (models.Example, error) {
+	example := models.Example{Name: name, Title: name, Category: defaultCategory}
+
+	lines := strings.Split(source, "\n")
+	i := 0
+	if strings.HasPrefix(lines[0], "//go:build ") {
+		i++
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+
+	var field string
+	var input, output []string
+	for ; i < len(lines) && strings.HasPrefix(lines[i], "//"); i++ {
+		line := strings.TrimPrefix(strings.TrimPrefix(lines[i], "//"), " ")
+
+		if field == "Output" {
+			output = append(output, line)
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		switch {
+		case ok && key == "Title":
+			example.Title = strings.TrimSpace(value)
+		case ok && key == "Category":
+			example.Category = strings.TrimSpace(value)
+		case ok && key == "Description":
+			example.Description = strings.TrimSpace(value)
+		case ok && key == "Order":
+			order, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || order < 1 {
+				return example, fmt.Errorf("invalid order %q", strings.TrimSpace(value))
+			}
+			example.Order = order
+		case ok && (key == "Input" || key == "Output"):
+			if strings.TrimSpace(value) != "" {
+				return example, fmt.Errorf("%s must start on the next line", key)
+			}
+		case field == "Input":
+			input = append(input, line)
+			continue
+		case field == "Description":
+			example.Description += " " + strings.TrimSpace(line)
+			continue
+		default:
+			return example, fmt.Errorf("line %d: unknown annotation %q", i+1, line)
+		}
+		field = key
+	}
+
+	if len(input) > 0 {
+		example.Input = strings.Join(input, "\n") + "\n"
+	}
+	if len(output) > 0 {
+		example.Output = strings.Join(output, "\n") + "\n"
+	}
+
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	example.Code = strings.Join(lines[i:], "\n")
+	if !strings.HasPrefix(example.Code, "package ") {
+		return example, fmt.Errorf("annotations must be followed by the package clause")
+	}
+	return example, nil
+}
@@ -0,0 +1,152 @@
+package examples
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+const program = "package main\n\nfunc main() {}\n"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    models.Example
+		wantErr string
+	}{
+		{
+			name:   "no annotations",
+			source: program,
+			want:   models.Example{Name: "x", Title: "x", Category: defaultCategory, Code: program},
+		},
+		{
+			name: "all annotations",
+			source: "//go:build ignore\n\n" +
+				"// Title: Name and color\n" +
+				"// Category: Interactive\n" +
+				"// Order: 6\n" +
+				"// Description: Reads answers\n" +
+				"// from standard input.\n" +
+				"// Input:\n" +
+				"// Gopher\n" +
+				"// blue\n" +
+				"// Output:\n" +
+				"// Name: Gopher\n" +
+				"//\n" +
+				"// Color: blue\n\n" +
+				program,
+			want: models.Example{
+				Name:        "x",
+				Title:       "Name and color",
+				Category:    "Interactive",
+				Order:       6,
+				Description: "Reads answers from standard input.",
+				Input:       "Gopher\nblue\n",
+				Output:      "Name: Gopher\n\nColor: blue\n",
+				Code:        program,
+			},
+		},
+		{
+			// Lines of input that look like annotations are still input.
+			name:   "input with colons",
+			source: "// Input:\n// a: b\n// Output:\n// ok\n" + program,
+			want:   models.Example{Name: "x", Title: "x", Category: defaultCategory, Input: "a: b\n", Output: "ok\n", Code: program},
+		},
+		{name: "bad order", source: "// Order: first\n" + program, wantErr: "invalid order"},
+		{name: "zero order", source: "// Order: 0\n" + program, wantErr: "invalid order"},
+		{name: "inline output", source: "// Output: hi\n" + program, wantErr: "next line"},
+		{name: "unknown annotation", source: "// Author: me\n" + program, wantErr: "unknown annotation"},
+		{name: "no package clause", source: "// Title: x\nfunc main() {}\n", wantErr: "package clause"},
+	}
+	for _, test := range tests {
+		got, err := parse("x", test.source)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: error %v, want one containing %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLoadOrder(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.go":  "// Order: 2\n// Category: Basics\n" + program,
+		"a.go":  "// Order: 2\n// Category: Basics\n" + program,
+		"c.go":  "// Order: 1\n// Category: Advanced\n" + program,
+		"z.go":  program,
+		"n.txt": "not an example",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	catalog, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, example := range catalog.All() {
+		names = append(names, example.Name)
+	}
+	// By order, then name; examples without an order come last.
+	if want := []string{"c", "a", "b", "z"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("order = %v, want %v", names, want)
+	}
+
+	var categories []string
+	for _, category := range catalog.Categories() {
+		categories = append(categories, category.Name)
+	}
+	if want := []string{"Advanced", "Basics", defaultCategory}; !reflect.DeepEqual(categories, want) {
+		t.Errorf("categories = %v, want %v", categories, want)
+	}
+
+	if _, ok := catalog.Get("a"); !ok {
+		t.Error("Get(a) found nothing")
+	}
+	if _, ok := catalog.Get("n"); ok {
+		t.Error("Get(n) found a non-Go file")
+	}
+}
+
+func TestLoadBundled(t *testing.T) {
+	catalog, err := Load("../../examples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.All()) == 0 {
+		t.Fatal("no bundled examples")
+	}
+	for _, example := range catalog.All() {
+		if example.Title == example.Name || example.Category == defaultCategory {
+			t.Errorf("%s has no title or category", example.Name)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bad.go"), []byte("// Order: x\n"+program), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "bad.go") {
+		t.Errorf("Load = %v, want an error naming bad.go", err)
+	}
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+)
+
+// Module is a program packaged as a standalone Go module.
+type Module struct {
+	// Code is the content of main.go.
+	Code string
+	// Files holds extra files, keyed by name.
+	Files map[string]string
+	// Source optionally names where the code came from, for the README.
+	Source string
+}
+
+// reserved are the files generated for every module.
+var reserved = map[string]bool{
+	"go.mod":    true,
+	"main.go":   true,
+	"README.md": true,
+}
+
+// Validate checks the extra files of m. Names must be plain file names that
+// do not clash with the generated files, and everything together must fit
+// in config.MaxCodeSize.
+func (m Module) Validate() error {
+	if len(m.Files) > config.MaxExportFiles {
+		return fmt.Errorf("too many files: %d, at most %d", len(m.Files), config.MaxExportFiles)
+	}
+
+	size := len(m.Code)
+	for name, content := range m.Files {
+		if name == "" || name != path.Base(name) || strings.ContainsAny(name, `\:`) || strings.HasPrefix(name, ".") {
+			return fmt.Errorf("invalid file name %q", name)
+		}
+		if reserved[name] {
+			return fmt.Errorf("file %s is generated and cannot be replaced", name)
+		}
+		size += len(content)
+	}
+	if size > config.MaxCodeSize {
+		return fmt.Errorf("module too large: %d bytes, at most %d", size, config.MaxCodeSize)
+	}
+	return nil
+}
+
+// WriteZip writes m to w as a zip archive with a go.mod, main.go, the extra
+// files and a README describing how to run it, all under a top-level
+// directory named after config.ExportModulePath.
+func (m Module) WriteZip(w io.Writer) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		"go.mod":    goMod(),
+		"main.go":   m.Code,
+		"README.md": m.readme(),
+	}
+	for name, content := range m.Files {
+		files[name] = content
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := zip.NewWriter(w)
+	modified := time.Now()
+	for _, name := range names {
+		header := &zip.FileHeader{
+			Name:     config.ExportModulePath + "/" + name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		}
+		file, err := archive.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %v", name, err)
+		}
+		if _, err := io.WriteString(file, files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip: %v", err)
+	}
+	return nil
+}
+
+func goMod() string {
+	return fmt.Sprintf("module %s\n\ngo %s\n", config.ExportModulePath, strings.TrimPrefix(config.GoVersion, "go"))
+}
+
+func (m Module) readme() string {
+	var b strings.Builder
+	version := strings.TrimPrefix(config.GoVersion, "go")
+
+	fmt.Fprintf(&b, "# %s\n\n", config.ExportModulePath)
+	if m.Source != "" {
+		fmt.Fprintf(&b, "Exported from the Go Playground (%s).\n\n", m.Source)
+	} else {
+		b.WriteString("Exported from the Go Playground.\n\n")
+	}
+	fmt.Fprintf(&b, "You need Go %s or later: https://go.dev/dl/\n\n", version)
+	b.WriteString("## Run\n\n")
+	b.WriteString("```sh\ngo run .\n```\n\n")
+	b.WriteString("## Build\n\n")
+	fmt.Fprintf(&b, "```sh\ngo build -o %[1]s .\n./%[1]s\n```\n\n", path.Base(config.ExportModulePath))
+	b.WriteString("If the program imports packages outside the standard library, run `go mod tidy` first to add them to go.mod.\n")
+	return b.String()
+}
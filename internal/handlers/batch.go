@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/AlexandruC0909/playground/internal/batch"
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// HandleBatch queues the submitted programs as a batch job and returns its
+// status. The job runs in the background; its ID, which only the submitter
+// learns, addresses its status and report.
+func HandleBatch(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, queue *batch.Queue) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxBatchRequestSize)
+
+	var request models.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	job, err := queue.Submit(request)
+	if !writeBatchError(w, err) {
+		return
+	}
+
+	w.Header().Set("Location", "/batch/"+job.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// HandleBatchStatus returns the progress of the batch job named by the id URL
+// parameter.
+func HandleBatchStatus(w http.ResponseWriter, r *http.Request, queue *batch.Queue) {
+	job, err := queue.Job(chi.URLParam(r, "id"), false)
+	if !writeBatchError(w, err) {
+		return
+	}
+	writeJSON(w, job)
+}
+
+// HandleBatchReport downloads the results of the batch job named by the id
+// URL parameter, as JSON or, with format=csv, as CSV. Reports of unfinished
+// jobs list the programs still pending.
+func HandleBatchReport(w http.ResponseWriter, r *http.Request, queue *batch.Queue) {
+	job, err := queue.Job(chi.URLParam(r, "id"), true)
+	if !writeBatchError(w, err) {
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "batch-"+job.ID+".json"))
+		writeJSON(w, job)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "batch-"+job.ID+".csv"))
+		if err := batch.WriteCSV(w, job); err != nil {
+			log.Printf("Failed to write batch report: %v\n", err)
+		}
+	default:
+		http.Error(w, fmt.Sprintf("Unknown report format %q", format), http.StatusBadRequest)
+	}
+}
+
+// HandleCancelBatch cancels the batch job named by the id URL parameter and
+// returns its status.
+func HandleCancelBatch(w http.ResponseWriter, r *http.Request, queue *batch.Queue) {
+	job, err := queue.Cancel(chi.URLParam(r, "id"))
+	if !writeBatchError(w, err) {
+		return
+	}
+	writeJSON(w, job)
+}
+
+func writeBatchError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, batch.ErrNotFound):
+		http.Error(w, "Batch job not found", http.StatusNotFound)
+	case errors.Is(err, batch.ErrBusy):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+	return false
+}
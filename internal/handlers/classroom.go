@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/classroom"
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// roomPingInterval is how often idle room streams get a comment, so that
+// proxies keep them open.
+const roomPingInterval = 30 * time.Second
+
+// HandleClassroom renders the page for creating and joining rooms.
+func HandleClassroom(w http.ResponseWriter, r *http.Request) {
+	renderPage(w, "classroom.html", models.RoomPage{})
+}
+
+// HandleRoomPage renders the editor of a student in the room named by the
+// code URL parameter, pre-filled with its starter code.
+func HandleRoomPage(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, err := rooms.Get(chi.URLParam(r, "code"))
+	if !writeRoomError(w, err) {
+		return
+	}
+	renderPage(w, "room.html", models.RoomPage{Code: room.Code(), StarterCode: room.StarterCode()})
+}
+
+// HandleRoomDashboard renders the instructor's view of a room. The page
+// authenticates with the instructor token kept by the browser that created
+// the room.
+func HandleRoomDashboard(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, err := rooms.Get(chi.URLParam(r, "code"))
+	if !writeRoomError(w, err) {
+		return
+	}
+	renderPage(w, "dashboard.html", models.RoomPage{Code: room.Code(), StarterCode: room.StarterCode()})
+}
+
+// HandleCreateRoom opens a room and returns its code with the instructor
+// token.
+func HandleCreateRoom(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, rooms *classroom.Rooms) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxRequestSize)
+
+	var request models.CreateRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	room, token, err := rooms.Create(request.StarterCode)
+	if !writeRoomError(w, err) {
+		return
+	}
+
+	writeJSON(w, models.CreateRoomResponse{
+		Code:            room.Code(),
+		InstructorToken: token,
+		URL:             "/classroom/" + room.Code(),
+		DashboardURL:    "/classroom/" + room.Code() + "/dashboard",
+	})
+}
+
+// HandleJoinRoom adds a student to the room named by the code URL parameter.
+func HandleJoinRoom(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, rooms *classroom.Rooms) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	room, err := rooms.Get(chi.URLParam(r, "code"))
+	if !writeRoomError(w, err) {
+		return
+	}
+
+	var request models.JoinRoomRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4096)).Decode(&request); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	id, token, err := room.Join(request.Name)
+	if !writeRoomError(w, err) {
+		return
+	}
+
+	writeJSON(w, models.JoinRoomResponse{StudentID: id, StudentToken: token, StarterCode: room.StarterCode()})
+}
+
+// HandleRoomEvents streams the events of the room named by the code URL
+// parameter as server-sent events. The token query parameter, since
+// EventSource cannot set headers, is the instructor's token, who sees every
+// student, or a student's, who sees the starter code and broadcasts.
+func HandleRoomEvents(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, err := rooms.Get(chi.URLParam(r, "code"))
+	if !writeRoomError(w, err) {
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	instructor := room.Instructor(token) == nil
+	if !instructor {
+		if _, err := room.Student(token); !writeRoomError(w, err) {
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	sub, unsubscribe, err := room.Subscribe(instructor)
+	if !writeRoomError(w, err) {
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ping := time.NewTicker(roomPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// HandleRoomCode records the latest code of the student whose token is in
+// the X-Student-Token header, for the instructor to follow.
+func HandleRoomCode(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, err := rooms.Get(chi.URLParam(r, "code"))
+	if !writeRoomError(w, err) {
+		return
+	}
+	id, err := room.Student(r.Header.Get("X-Student-Token"))
+	if !writeRoomError(w, err) {
+		return
+	}
+
+	request, ok := decodeRoomCode(w, r)
+	if !ok {
+		return
+	}
+	if !writeRoomError(w, room.UpdateCode(id, request.Code)) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleRoomStarter replaces the starter code of a room and pushes it to
+// its students.
+func HandleRoomStarter(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, ok := instructorRoom(w, r, rooms)
+	if !ok {
+		return
+	}
+	request, ok := decodeRoomCode(w, r)
+	if !ok {
+		return
+	}
+	if !writeRoomError(w, room.SetStarterCode(request.Code)) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleRoomBroadcast sends a snippet, with an optional message, to the
+// students of a room.
+func HandleRoomBroadcast(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, ok := instructorRoom(w, r, rooms)
+	if !ok {
+		return
+	}
+	request, ok := decodeRoomCode(w, r)
+	if !ok {
+		return
+	}
+	if !writeRoomError(w, room.Broadcast(request.Code, request.Message)) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleRoomState returns a room with the latest code and run of each
+// student.
+func HandleRoomState(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, ok := instructorRoom(w, r, rooms)
+	if !ok {
+		return
+	}
+	writeJSON(w, room.Snapshot(true))
+}
+
+// HandleCloseRoom closes a room, disconnecting its students.
+func HandleCloseRoom(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, ok := instructorRoom(w, r, rooms)
+	if !ok {
+		return
+	}
+	rooms.Remove(room)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// roomObserver returns the observer recording a run for the room student
+// named by the X-Room and X-Student-Token headers, if any. Runs of students
+// whose room has closed are not recorded.
+func roomObserver(r *http.Request, rooms *classroom.Rooms, code string) func(models.ProgramOutput) {
+	if r.Header.Get("X-Room") == "" {
+		return nil
+	}
+	room, err := rooms.Get(r.Header.Get("X-Room"))
+	if err != nil {
+		return nil
+	}
+	id, err := room.Student(r.Header.Get("X-Student-Token"))
+	if err != nil {
+		return nil
+	}
+	observer, err := room.StartRun(id, code)
+	if err != nil {
+		log.Printf("Failed to record room run: %v\n", err)
+		return nil
+	}
+	return observer
+}
+
+// instructorRoom returns the room named by the code URL parameter if the
+// X-Instructor-Token header carries its instructor's token.
+func instructorRoom(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) (*classroom.Room, bool) {
+	room, err := rooms.Get(chi.URLParam(r, "code"))
+	if !writeRoomError(w, err) {
+		return nil, false
+	}
+	if !writeRoomError(w, room.Instructor(r.Header.Get("X-Instructor-Token"))) {
+		return nil, false
+	}
+	return room, true
+}
+
+func decodeRoomCode(w http.ResponseWriter, r *http.Request) (models.RoomCodeRequest, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxRequestSize)
+
+	var request models.RoomCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return request, false
+	}
+	return request, true
+}
+
+func writeRoomError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, classroom.ErrNotFound):
+		http.Error(w, "Room not found", http.StatusNotFound)
+	case errors.Is(err, classroom.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, classroom.ErrFull):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, classroom.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/utils"
+)
+
+// debugSession is the debugger of a session and the token that controls it.
+// The debugger is set once the program has started.
+type debugSession struct {
+	token    string
+	debugger atomic.Pointer[docker.Debugger]
+}
+
+// HandleDebug starts a debug session. Like /run it returns a session ID whose
+// output is streamed by /program-output and which accepts /send-input; the
+// same ID and the returned debug token are used by the /debug/* endpoints to
+// control the debugger.
+func HandleDebug(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, activeSessions *sync.Map, debugSessions *sync.Map, executor *docker.Executor) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	var request models.DebugRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	token, err := newDebugToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.CleanupPreviousSession(activeSessions, r)
+
+	sessionID := atomic.AddUint64(&sessionCounter, 1)
+	session := models.NewSession()
+	debug := &debugSession{token: token}
+	activeSessions.Store(sessionID, session)
+	debugSessions.Store(sessionID, debug)
+
+	go executeDebug(request, session, sessionID, debug, executor, activeSessions, debugSessions)
+
+	writeJSON(w, models.DebugResponse{SessionID: sessionID, DebugToken: token})
+}
+
+func executeDebug(request models.DebugRequest, session *models.ProgramSession, sessionID uint64, debug *debugSession, executor *docker.Executor, activeSessions *sync.Map, debugSessions *sync.Map) {
+	start := time.Now()
+
+	defer utils.LogTiming("Debug session", start)
+	defer func() {
+		session.Close()
+		activeSessions.Delete(sessionID)
+		debugSessions.Delete(sessionID)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.DebugTimeoutMinutes*time.Minute)
+	defer cancel()
+
+	session.Code = request.Code
+	if err := utils.ValidateAndPrepare(request.Code, session); err != nil {
+		utils.SendError(session, err.Error())
+		return
+	}
+
+	debugger, wait, err := executor.Debug(ctx, session)
+	if err != nil {
+		utils.SendError(session, err.Error())
+		return
+	}
+	defer debugger.Close()
+
+	for _, line := range request.Breakpoints {
+		if _, err := debugger.CreateBreakpoint(line); err != nil {
+			utils.SendError(session, err.Error())
+			return
+		}
+	}
+
+	debug.debugger.Store(debugger)
+
+	// Closing the session, e.g. when the user starts another run, kills the
+	// debugged program.
+	go func() {
+		<-session.Done
+		debugger.Close()
+	}()
+
+	if err := wait(); err != nil {
+		utils.SendError(session, err.Error())
+	}
+}
+
+func HandleDebugCommand(w http.ResponseWriter, r *http.Request, debugSessions *sync.Map) {
+	debugger, ok := loadDebugger(w, r, debugSessions)
+	if !ok {
+		return
+	}
+
+	var command models.DebugCommand
+	if err := json.NewDecoder(r.Body).Decode(&command); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	state, err := debugger.Command(command.Command, command.GoroutineID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, state)
+}
+
+// HandleDebugBreakpoints creates a breakpoint on POST and clears the
+// breakpoint given by the id query parameter on DELETE.
+func HandleDebugBreakpoints(w http.ResponseWriter, r *http.Request, debugSessions *sync.Map) {
+	debugger, ok := loadDebugger(w, r, debugSessions)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		id, err := strconv.Atoi(r.URL.Query().Get("id"))
+		if err != nil {
+			http.Error(w, "Invalid breakpoint ID", http.StatusBadRequest)
+			return
+		}
+		if err := debugger.ClearBreakpoint(id); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var request models.BreakpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	breakpoint, err := debugger.CreateBreakpoint(request.Line)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, breakpoint)
+}
+
+func HandleDebugGoroutines(w http.ResponseWriter, r *http.Request, debugSessions *sync.Map) {
+	debugger, ok := loadDebugger(w, r, debugSessions)
+	if !ok {
+		return
+	}
+
+	goroutines, err := debugger.Goroutines()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, goroutines)
+}
+
+func HandleDebugStack(w http.ResponseWriter, r *http.Request, debugSessions *sync.Map) {
+	debugger, ok := loadDebugger(w, r, debugSessions)
+	if !ok {
+		return
+	}
+
+	goroutineID, _, err := parseFrameScope(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	frames, err := debugger.Stack(goroutineID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, frames)
+}
+
+func HandleDebugVariables(w http.ResponseWriter, r *http.Request, debugSessions *sync.Map) {
+	debugger, ok := loadDebugger(w, r, debugSessions)
+	if !ok {
+		return
+	}
+
+	goroutineID, frame, err := parseFrameScope(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var variables []models.DebugVariable
+	if expr := r.URL.Query().Get("expr"); expr != "" {
+		variable, err := debugger.Eval(goroutineID, frame, expr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		variables = []models.DebugVariable{variable}
+	} else {
+		variables, err = debugger.Variables(goroutineID, frame)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	writeJSON(w, variables)
+}
+
+// loadDebugger looks up the debugger of the session given by the sessionId
+// query parameter, writing an error response if there is none or if the
+// X-Debug-Token header does not carry the session's debug token.
+func loadDebugger(w http.ResponseWriter, r *http.Request, debugSessions *sync.Map) (*docker.Debugger, bool) {
+	sessionID, err := strconv.ParseUint(r.URL.Query().Get("sessionId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	value, ok := debugSessions.Load(sessionID)
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil, false
+	}
+	debug := value.(*debugSession)
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Debug-Token")), []byte(debug.token)) != 1 {
+		http.Error(w, "Invalid debug token", http.StatusForbidden)
+		return nil, false
+	}
+	debugger := debug.debugger.Load()
+	if debugger == nil {
+		http.Error(w, "Debugger is starting", http.StatusConflict)
+		return nil, false
+	}
+	return debugger, true
+}
+
+// newDebugToken returns a random token for controlling a debug session.
+func newDebugToken() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate debug token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// parseFrameScope reads the optional goroutineId and frame query parameters.
+func parseFrameScope(r *http.Request) (int64, int, error) {
+	var goroutineID int64
+	var frame int
+	var err error
+
+	if s := r.URL.Query().Get("goroutineId"); s != "" {
+		if goroutineID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid goroutine ID")
+		}
+	}
+	if s := r.URL.Query().Get("frame"); s != "" {
+		if frame, err = strconv.Atoi(s); err != nil {
+			return 0, 0, fmt.Errorf("invalid frame")
+		}
+	}
+	return goroutineID, frame, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/docker"
+)
+
+func TestLoadDebugger(t *testing.T) {
+	var debugSessions sync.Map
+	ready := &debugSession{token: "ready-token"}
+	debugger := &docker.Debugger{}
+	ready.debugger.Store(debugger)
+	debugSessions.Store(uint64(1), ready)
+	debugSessions.Store(uint64(2), &debugSession{token: "starting-token"})
+
+	tests := []struct {
+		name      string
+		sessionID string
+		token     string
+		status    int
+	}{
+		{"ready", "1", "ready-token", http.StatusOK},
+		{"invalid session ID", "x", "ready-token", http.StatusBadRequest},
+		{"unknown session", "3", "ready-token", http.StatusNotFound},
+		{"no token", "1", "", http.StatusForbidden},
+		{"other session's token", "1", "starting-token", http.StatusForbidden},
+		{"starting", "2", "starting-token", http.StatusConflict},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/debug/goroutines?sessionId="+test.sessionID, nil)
+		if test.token != "" {
+			r.Header.Set("X-Debug-Token", test.token)
+		}
+		w := httptest.NewRecorder()
+		got, ok := loadDebugger(w, r, &debugSessions)
+		if ok != (test.status == http.StatusOK) || w.Code != test.status {
+			t.Errorf("%s: loadDebugger = %v with status %d, want status %d", test.name, ok, w.Code, test.status)
+		}
+		if ok && got != debugger {
+			t.Errorf("%s: loadDebugger returned another debugger", test.name)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AlexandruC0909/playground/internal/examples"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// HandleExamples lists the bundled examples in menu order.
+func HandleExamples(w http.ResponseWriter, r *http.Request, catalog *examples.Catalog) {
+	all := catalog.All()
+	if all == nil {
+		all = []models.Example{}
+	}
+	writeJSON(w, all)
+}
+
+// HandleExample returns one bundled example.
+func HandleExample(w http.ResponseWriter, r *http.Request, catalog *examples.Catalog) {
+	example, ok := catalog.Get(chi.URLParam(r, "name"))
+	if !ok {
+		http.Error(w, "Example not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, example)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/export"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/snippets"
+	"github.com/go-chi/chi/v5"
+)
+
+// HandleExport downloads the submitted code, plus any extra files, as a zip
+// of a ready-to-build Go module.
+func HandleExport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxRequestSize)
+
+	var requestData models.ExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	writeModule(w, export.Module{Code: requestData.Code, Files: requestData.Files}, config.ExportModulePath+".zip")
+}
+
+// HandleSnippetExport downloads a shared snippet as a zip of a Go module.
+// Encrypted snippets can only be exported from the editor, which holds the
+// key.
+func HandleSnippetExport(w http.ResponseWriter, r *http.Request, store *snippets.Store) {
+	snippet, err := store.View(chi.URLParam(r, "id"))
+	if !writeSnippetError(w, err) {
+		return
+	}
+	if snippet.Encrypted {
+		http.Error(w, "Encrypted snippets can only be downloaded from the editor", http.StatusBadRequest)
+		return
+	}
+
+	module := export.Module{Code: snippet.Code, Source: "snippet " + snippet.ID}
+	writeModule(w, module, fmt.Sprintf("%s-%s.zip", config.ExportModulePath, snippet.ID))
+}
+
+func writeModule(w http.ResponseWriter, module export.Module, filename string) {
+	if err := module.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := module.WriteZip(&buf); err != nil {
+		log.Printf("Failed to export module: %v\n", err)
+		http.Error(w, "Error exporting code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Write(buf.Bytes())
+}
@@ -5,14 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"go/format"
+	"html/template"
+	"log"
 	"net/http"
 	"strconv"
 	"sync"
 	"sync/atomic"
-	"text/template"
 	"time"
 
+	"github.com/AlexandruC0909/playground/internal/classroom"
+	"github.com/AlexandruC0909/playground/internal/config"
 	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/examples"
+	"github.com/AlexandruC0909/playground/internal/imports"
 	"github.com/AlexandruC0909/playground/internal/models"
 	"github.com/AlexandruC0909/playground/internal/utils"
 	"github.com/AlexandruC0909/playground/templates"
@@ -23,27 +28,69 @@ var (
 	sessionCounter uint64
 )
 
-func HandleHome(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFS(templates.Templates, "form.html")
+// HandleHome renders the editor with the examples menu. It is pre-filled
+// with the program in the code query parameter (see utils.DecodeCode) or the
+// example named by the example parameter, so documentation can link to
+// ready-to-run code.
+func HandleHome(w http.ResponseWriter, r *http.Request, catalog *examples.Catalog) {
+	page := models.HomePage{Examples: catalog.Categories()}
+
+	query := r.URL.Query()
+	switch {
+	case query.Get("code") != "":
+		code, err := utils.DecodeCode(query.Get("code"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		page.Code = code
+	case query.Get("example") != "":
+		example, ok := catalog.Get(query.Get("example"))
+		if !ok {
+			http.Error(w, "Example not found", http.StatusNotFound)
+			return
+		}
+		page.Code = example.Code
+		page.Example = example.Name
+	default:
+		if all := catalog.All(); len(all) > 0 {
+			page.Code = all[0].Code
+			page.Example = all[0].Name
+		}
+	}
+
+	renderHome(w, page)
+}
+
+// renderHome executes form.html with page. html/template escapes the code
+// placed in the editor.
+func renderHome(w http.ResponseWriter, page models.HomePage) {
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'self'")
+	renderPage(w, "form.html", page)
+}
+
+func renderPage(w http.ResponseWriter, name string, page interface{}) {
+	tmpl, err := template.ParseFS(templates.Templates, name)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	err = tmpl.Execute(w, nil)
+	err = tmpl.Execute(w, page)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
-func HandleSave(w http.ResponseWriter, r *http.Request) {
+// HandleSave formats the submitted code. With fixImports set it also adds
+// missing standard library imports and removes unused ones, reporting each
+// change.
+func HandleSave(w http.ResponseWriter, r *http.Request, executor *docker.Executor) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var requestData struct {
-		Code string `json:"code"`
-	}
+	var requestData models.FormatRequest
 
 	err := json.NewDecoder(r.Body).Decode(&requestData)
 	if err != nil {
@@ -51,20 +98,79 @@ func HandleSave(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	formatted, err := format.Source([]byte(requestData.Code))
+	var responseData models.FormatResponse
+
+	if requestData.FixImports {
+		ctx, cancel := context.WithTimeout(r.Context(), config.TimeoutSeconds*time.Second)
+		defer cancel()
+
+		index, err := executor.StdlibIndex(ctx)
+		if err != nil {
+			log.Printf("Failed to load stdlib index: %v\n", err)
+			http.Error(w, "Import fixing is not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		fixed, changes, err := imports.Fix([]byte(requestData.Code), index)
+		if err != nil {
+			writeFormatError(w, requestData.Code)
+			return
+		}
+		responseData.Code = string(fixed)
+		responseData.Changes = changes
+	} else {
+		formatted, err := format.Source([]byte(requestData.Code))
+		if err != nil {
+			writeFormatError(w, requestData.Code)
+			return
+		}
+		responseData.Code = string(formatted)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responseData)
+}
+
+// HandleCheck parses and type checks the submitted code without running it,
+// for showing errors in the editor while typing.
+func HandleCheck(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxRequestSize)
+
+	requestData, err := utils.ParseRequestBody(r)
 	if err != nil {
-		http.Error(w, "Error formatting code", http.StatusInternalServerError)
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
 		return
 	}
 
-	responseData := struct {
-		Code string `json:"code"`
-	}{
-		Code: string(formatted),
+	response := models.CheckResponse{Errors: utils.CheckCode(requestData.Code)}
+	if response.Errors == nil {
+		response.Errors = []models.SourceError{}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(responseData)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeFormatError reports the syntax errors that made formatting code fail.
+func writeFormatError(w http.ResponseWriter, code string) {
+	errors, partial := utils.SyntaxErrors([]byte(code))
+	if len(errors) == 0 {
+		http.Error(w, "Error formatting code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(models.FormatErrorResponse{
+		Error:   "Code contains syntax errors",
+		Errors:  errors,
+		Partial: string(partial),
+	})
 }
 
 func HandleHealth(w http.ResponseWriter, r *http.Request, containerID string, localClient *client.Client) {
@@ -171,11 +277,14 @@ func cacheControlWrapper(h http.Handler) http.Handler {
 	})
 }
 
-func HandleRun(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, activeSessions *sync.Map, executor *docker.Executor) {
+// HandleRun starts running the submitted code and returns the session
+// streaming its output. Runs of classroom students, named by the X-Room and
+// X-Student-Token headers, are recorded for their instructor.
+func HandleRun(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, activeSessions *sync.Map, executor *docker.Executor, rooms *classroom.Rooms) {
 	start := time.Now()
 	defer utils.LogTiming("Total request handling", start)
 
-	sessionID, err := handleRequest(r, rateLimiter, activeSessions, executor)
+	sessionID, err := handleRequest(r, rateLimiter, activeSessions, executor, rooms)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -184,7 +293,7 @@ func HandleRun(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLi
 	json.NewEncoder(w).Encode(map[string]uint64{"sessionId": sessionID})
 }
 
-func handleRequest(r *http.Request, rateLimiter *utils.RateLimiter, activeSessions *sync.Map, executor *docker.Executor) (uint64, error) {
+func handleRequest(r *http.Request, rateLimiter *utils.RateLimiter, activeSessions *sync.Map, executor *docker.Executor, rooms *classroom.Rooms) (uint64, error) {
 	ip := utils.ExtractIP(r)
 
 	if err := utils.CheckRateLimit(rateLimiter, ip); err != nil {
@@ -200,15 +309,16 @@ func handleRequest(r *http.Request, rateLimiter *utils.RateLimiter, activeSessio
 
 	sessionID := atomic.AddUint64(&sessionCounter, 1)
 	session := models.NewSession()
+	session.Observer = roomObserver(r, rooms, requestData.Code)
 
 	activeSessions.Store(sessionID, session)
 
-	go executeCode(requestData.Code, session, sessionID, executor, activeSessions)
+	go executeCode(requestData, session, sessionID, executor, activeSessions)
 
 	return sessionID, nil
 }
 
-func executeCode(code string, session *models.ProgramSession, sessionID uint64, executor *docker.Executor, activeSessions *sync.Map) {
+func executeCode(request models.CodeRequest, session *models.ProgramSession, sessionID uint64, executor *docker.Executor, activeSessions *sync.Map) {
 	start := time.Now()
 
 	defer utils.LogTiming("Code execution", start)
@@ -220,17 +330,29 @@ func executeCode(code string, session *models.ProgramSession, sessionID uint64,
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := utils.ValidateAndPrepare(code, session); err != nil {
+	session.Code = request.Code
+	if err := utils.ValidateAndPrepare(request.Code, session); err != nil {
+		utils.SendError(session, err.Error())
+		return
+	}
+
+	// Concurrent runs each get their own workspace, so that one never builds
+	// another's main.go or profiling wrapper.
+	opts := request.RunOptions
+	dir, remove, err := executor.Workspace(ctx, opts)
+	if err != nil {
 		utils.SendError(session, err.Error())
 		return
 	}
+	defer remove()
+	opts.Dir = dir
 
-	if err := executor.Compile(ctx, code); err != nil {
+	if err := executor.Compile(ctx, request.Code, opts); err != nil {
 		utils.SendError(session, err.Error())
 		return
 	}
 
-	if err := executor.Run(ctx, session); err != nil {
+	if err := executor.Run(ctx, session, opts); err != nil {
 		utils.SendError(session, err.Error())
 		return
 	}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/lessons"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// HandleLearn lists the lessons.
+func HandleLearn(w http.ResponseWriter, r *http.Request, catalog *lessons.Catalog) {
+	renderPage(w, "learn.html", models.LearnPage{Lessons: catalog.Lessons()})
+}
+
+// HandleLesson redirects to the first page of a lesson.
+func HandleLesson(w http.ResponseWriter, r *http.Request, catalog *lessons.Catalog) {
+	slug := chi.URLParam(r, "lesson")
+	for _, lesson := range catalog.Lessons() {
+		if lesson.Slug == slug {
+			http.Redirect(w, r, lessons.Path(lesson.Slug, lesson.Pages[0].Slug), http.StatusFound)
+			return
+		}
+	}
+	http.Error(w, "Lesson not found", http.StatusNotFound)
+}
+
+// HandleLessonPage renders a lesson page with its starter code in the editor.
+func HandleLessonPage(w http.ResponseWriter, r *http.Request, catalog *lessons.Catalog) {
+	lesson, page, ok := catalog.Page(chi.URLParam(r, "lesson"), chi.URLParam(r, "page"))
+	if !ok {
+		http.Error(w, "Lesson page not found", http.StatusNotFound)
+		return
+	}
+
+	data := models.LearnPage{Lessons: catalog.Lessons(), Lesson: lesson, Page: page}
+	data.Prev, data.Next = catalog.Neighbours(lesson.Slug, page.Slug)
+	renderPage(w, "lesson.html", data)
+}
+
+// HandleLessonCheck runs the submitted solution to a lesson page's exercise
+// in the sandbox, or its hidden tests against it, and reports whether it
+// passes. The exercise's expected output, checks and tests stay on the
+// server; only the messages written for students are returned.
+func HandleLessonCheck(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, catalog *lessons.Catalog, executor *docker.Executor) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	_, page, ok := catalog.Page(chi.URLParam(r, "lesson"), chi.URLParam(r, "page"))
+	if !ok || page.Exercise == nil {
+		http.Error(w, "Exercise not found", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxRequestSize)
+	var requestData models.ExerciseRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, lessons.Evaluate(r.Context(), executor, *page.Exercise, requestData.Code))
+}
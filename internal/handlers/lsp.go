@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/utils"
+	"github.com/gorilla/websocket"
+)
+
+// lspMethods are the LSP methods editors may send. Everything else, notably
+// workspace/executeCommand, is rejected so that clients cannot make gopls run
+// the go command or edit files on their behalf.
+var lspMethods = map[string]bool{
+	"initialize":                     true,
+	"initialized":                    true,
+	"shutdown":                       true,
+	"exit":                           true,
+	"$/cancelRequest":                true,
+	"$/setTrace":                     true,
+	"textDocument/didOpen":           true,
+	"textDocument/didChange":         true,
+	"textDocument/didClose":          true,
+	"textDocument/didSave":           true,
+	"textDocument/completion":        true,
+	"completionItem/resolve":         true,
+	"textDocument/hover":             true,
+	"textDocument/signatureHelp":     true,
+	"textDocument/definition":        true,
+	"textDocument/typeDefinition":    true,
+	"textDocument/documentHighlight": true,
+}
+
+var (
+	lspSlots    = make(chan struct{}, config.MaxLSPSessions)
+	lspUpgrader = websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+	}
+)
+
+// lspMessage holds the parts of a JSON-RPC message inspected by the bridge.
+type lspMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params struct {
+		RootURI      string `json:"rootUri,omitempty"`
+		RootPath     string `json:"rootPath,omitempty"`
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		WorkspaceFolders []struct {
+			URI string `json:"uri"`
+		} `json:"workspaceFolders,omitempty"`
+	} `json:"params"`
+}
+
+// HandleLanguageServer bridges a WebSocket to a gopls process. Each WebSocket
+// text message carries one JSON-RPC message, without the Content-Length
+// header of the LSP base protocol. Documents live under config.LSPRootURI,
+// which must also be the workspace root sent in initialize. The editor sends
+// its code with textDocument/didOpen; nothing else seeds the workspace.
+func HandleLanguageServer(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, executor *docker.Executor) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	select {
+	case lspSlots <- struct{}{}:
+		defer func() { <-lspSlots }()
+	default:
+		http.Error(w, "Too many language server sessions", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := lspUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade language server connection: %v\n", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(config.MaxLSPMessageSize)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.LSPTimeoutMinutes*time.Minute)
+	defer cancel()
+
+	server, err := executor.LanguageServer(ctx)
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		return
+	}
+	defer server.Close()
+
+	var writeMu sync.Mutex
+	go func() {
+		defer cancel()
+		for {
+			message, err := server.ReadMessage()
+			if err != nil {
+				return
+			}
+			writeMu.Lock()
+			err = conn.WriteMessage(websocket.TextMessage, message)
+			writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	// Closing the WebSocket unblocks the reader below once the session
+	// expires or gopls goes away.
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg lspMessage
+		if err := json.Unmarshal(message, &msg); err != nil {
+			return
+		}
+
+		if err := checkLSPMessage(msg); err != nil {
+			if len(msg.ID) == 0 {
+				continue
+			}
+			reply, _ := json.Marshal(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      msg.ID,
+				"error":   map[string]interface{}{"code": -32601, "message": err.Error()},
+			})
+			writeMu.Lock()
+			err = conn.WriteMessage(websocket.TextMessage, reply)
+			writeMu.Unlock()
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		if err := server.WriteMessage(message); err != nil {
+			return
+		}
+	}
+}
+
+// checkLSPMessage rejects methods outside lspMethods and documents or
+// workspaces outside config.LSPRootURI. Responses to requests made by gopls
+// carry no method and are always forwarded.
+func checkLSPMessage(msg lspMessage) error {
+	if msg.Method == "" {
+		return nil
+	}
+	if !lspMethods[msg.Method] {
+		return fmt.Errorf("method %s is not supported", msg.Method)
+	}
+
+	uris := []string{msg.Params.RootURI, msg.Params.TextDocument.URI}
+	for _, folder := range msg.Params.WorkspaceFolders {
+		uris = append(uris, folder.URI)
+	}
+	for _, uri := range uris {
+		if uri != "" && !inLSPRoot(uri) {
+			return fmt.Errorf("%s is outside the workspace", uri)
+		}
+	}
+	if msg.Params.RootPath != "" {
+		return fmt.Errorf("rootPath is not supported, use rootUri")
+	}
+	return nil
+}
+
+// inLSPRoot reports whether uri names config.LSPRootURI or a file under it
+// once its path is cleaned, so that dot segments cannot escape the root.
+func inLSPRoot(uri string) bool {
+	root, err := url.Parse(config.LSPRootURI)
+	if err != nil {
+		return false
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != root.Scheme || u.Host != root.Host || u.Opaque != "" {
+		return false
+	}
+	p := path.Clean(u.Path)
+	return p == root.Path || strings.HasPrefix(p, root.Path+"/")
+}
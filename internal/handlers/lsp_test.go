@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckLSPMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		ok      bool
+	}{
+		{"response", `{"id":1,"result":null}`, true},
+		{"root", `{"method":"initialize","params":{"rootUri":"file:///playground"}}`, true},
+		{"document", `{"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///playground/main.go"}}}`, true},
+		{"dot segments inside", `{"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///playground/a/../main.go"}}}`, true},
+		{"unsupported method", `{"method":"workspace/executeCommand"}`, false},
+		{"outside", `{"method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///etc/passwd"}}}`, false},
+		{"root prefix", `{"method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///playground2/main.go"}}}`, false},
+		{"dot segments", `{"method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///playground/../etc/passwd"}}}`, false},
+		{"encoded dot segments", `{"method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///playground/%2e%2e/etc/passwd"}}}`, false},
+		{"other host", `{"method":"textDocument/didOpen","params":{"textDocument":{"uri":"file://host/playground/main.go"}}}`, false},
+		{"workspace folder", `{"method":"initialize","params":{"workspaceFolders":[{"uri":"file:///playground/.."}]}}`, false},
+		{"root path", `{"method":"initialize","params":{"rootPath":"/playground"}}`, false},
+	}
+	for _, test := range tests {
+		var msg lspMessage
+		if err := json.Unmarshal([]byte(test.message), &msg); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if err := checkLSPMessage(msg); (err == nil) != test.ok {
+			t.Errorf("%s: checkLSPMessage = %v, want ok %v", test.name, err, test.ok)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/examples"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/snippets"
+	"github.com/AlexandruC0909/playground/internal/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+// HandleShare stores the submitted code and returns its permanent link. With
+// a parent set, the code is saved as a new revision of that snippet.
+// Encrypted code is stored as is; the key stays in the link's fragment, which
+// browsers never send to the server.
+func HandleShare(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, store *snippets.Store) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxRequestSize)
+
+	var request models.ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+	if request.Code == "" {
+		http.Error(w, "No code to share", http.StatusBadRequest)
+		return
+	}
+
+	if request.ExpiresIn < 0 || request.MaxViews < 0 {
+		http.Error(w, "Invalid expiry", http.StatusBadRequest)
+		return
+	}
+
+	snippet, token, err := store.Save(request.Code, request.Parent, snippets.Options{
+		Encrypted: request.Encrypted,
+		TTL:       time.Duration(request.ExpiresIn) * time.Second,
+		MaxViews:  request.MaxViews,
+		ReadOnly:  request.ReadOnly,
+	})
+	if !writeSnippetError(w, err) {
+		return
+	}
+
+	writeJSON(w, shareResponse(snippet, token))
+}
+
+// HandleFork creates a new revision of the snippet named by the id URL
+// parameter, to be edited independently of later revisions of the original.
+func HandleFork(w http.ResponseWriter, r *http.Request, rateLimiter *utils.RateLimiter, store *snippets.Store) {
+	if err := utils.CheckRateLimit(rateLimiter, utils.ExtractIP(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	snippet, token, err := store.Fork(chi.URLParam(r, "id"))
+	if !writeSnippetError(w, err) {
+		return
+	}
+
+	writeJSON(w, shareResponse(snippet, token))
+}
+
+// HandleUpdateSnippet replaces the code of the snippet named by the id URL
+// parameter. The X-Edit-Token header must carry the token returned when the
+// snippet was shared.
+func HandleUpdateSnippet(w http.ResponseWriter, r *http.Request, store *snippets.Store) {
+	r.Body = http.MaxBytesReader(w, r.Body, config.MaxRequestSize)
+
+	var request models.UpdateSnippetRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error decoding JSON", http.StatusBadRequest)
+		return
+	}
+	if request.Code == "" {
+		http.Error(w, "No code to share", http.StatusBadRequest)
+		return
+	}
+
+	snippet, err := store.Update(chi.URLParam(r, "id"), r.Header.Get("X-Edit-Token"), request.Code)
+	if !writeSnippetError(w, err) {
+		return
+	}
+
+	writeJSON(w, shareResponse(snippet, ""))
+}
+
+// HandleDeleteSnippet deletes the snippet named by the id URL parameter,
+// authorized like HandleUpdateSnippet.
+func HandleDeleteSnippet(w http.ResponseWriter, r *http.Request, store *snippets.Store) {
+	err := store.Delete(chi.URLParam(r, "id"), r.Header.Get("X-Edit-Token"))
+	if !writeSnippetError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleSnippetHistory returns the lineage of the snippet named by the id URL
+// parameter and the diff to it from the revision given by the from query
+// parameter, which defaults to its parent. Without a from parameter, a parent
+// that no longer exists leaves the diff out.
+func HandleSnippetHistory(w http.ResponseWriter, r *http.Request, store *snippets.Store) {
+	lineage, err := store.Lineage(chi.URLParam(r, "id"))
+	if !writeSnippetError(w, err) {
+		return
+	}
+
+	snippet := lineage[len(lineage)-1]
+	var history models.SnippetHistory
+	for _, revision := range lineage {
+		history.Lineage = append(history.Lineage, models.SnippetRevision{
+			ID:        revision.ID,
+			Parent:    revision.Parent,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+
+	from := r.URL.Query().Get("from")
+	explicit := from != ""
+	if !explicit {
+		from = snippet.Parent
+	}
+	// The server cannot diff ciphertext, and diffs must not reveal snippets
+	// that burn after reading.
+	if from != "" && !snippets.Private(snippet) {
+		fromSnippet, err := store.Get(from)
+		if errors.Is(err, snippets.ErrNotFound) && !explicit {
+			writeJSON(w, history)
+			return
+		}
+		if !writeSnippetError(w, err) {
+			return
+		}
+		if !snippets.Private(fromSnippet) {
+			history.From = from
+			history.Diff = snippets.Diff(fromSnippet, snippet)
+		}
+	}
+
+	writeJSON(w, history)
+}
+
+// HandleSnippet renders the editor pre-filled with the snippet named by the
+// id URL parameter, counting the view.
+func HandleSnippet(w http.ResponseWriter, r *http.Request, store *snippets.Store, catalog *examples.Catalog) {
+	snippet, err := store.View(chi.URLParam(r, "id"))
+	if !writeSnippetError(w, err) {
+		return
+	}
+
+	if snippets.Private(snippet) {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+
+	page := snippetPage(snippet)
+	page.Examples = catalog.Categories()
+	renderHome(w, page)
+}
+
+// HandleEmbed renders a shared snippet in the minimal embed.html page, meant
+// to be framed by the sites listed in config.EmbedAllowedOrigins.
+func HandleEmbed(w http.ResponseWriter, r *http.Request, store *snippets.Store) {
+	snippet, err := store.View(chi.URLParam(r, "id"))
+	if !writeSnippetError(w, err) {
+		return
+	}
+
+	if snippets.Private(snippet) {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	ancestors := append([]string{"'self'"}, config.EmbedAllowedOrigins...)
+	w.Header().Set("Content-Security-Policy", "frame-ancestors "+strings.Join(ancestors, " "))
+
+	renderPage(w, "embed.html", snippetPage(snippet))
+}
+
+func snippetPage(snippet models.Snippet) models.HomePage {
+	page := models.HomePage{SnippetID: snippet.ID}
+	if snippet.Encrypted {
+		page.Ciphertext = snippet.Code
+	} else {
+		page.Code = snippet.Code
+	}
+	return page
+}
+
+// HandleSnippetStats reports snippet storage usage.
+func HandleSnippetStats(w http.ResponseWriter, r *http.Request, store *snippets.Store) {
+	writeJSON(w, store.Stats())
+}
+
+func shareResponse(snippet models.Snippet, token string) models.ShareResponse {
+	return models.ShareResponse{
+		ID:        snippet.ID,
+		URL:       "/p/" + snippet.ID,
+		EditToken: token,
+		ExpiresAt: snippet.ExpiresAt,
+	}
+}
+
+// writeSnippetError writes the response for a snippet store error. It
+// returns true if there was no error.
+func writeSnippetError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, snippets.ErrNotFound):
+		http.Error(w, "Snippet not found", http.StatusNotFound)
+	case errors.Is(err, snippets.ErrParentNotFound):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, snippets.ErrInvalidCiphertext):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, snippets.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, snippets.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		log.Printf("Snippet store error: %v\n", err)
+		http.Error(w, "Snippet storage error", http.StatusInternalServerError)
+	}
+	return false
+}
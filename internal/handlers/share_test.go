@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/snippets"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandleSnippetHistory(t *testing.T) {
+	backend := snippets.NewMemoryBackend()
+	store := snippets.NewStore(backend)
+	defer store.Close()
+
+	parent, _, err := store.Save("package main\n", "", snippets.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, _, err := store.Save("package main\n\nfunc main() {}\n", parent.ID, snippets.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	r.Get("/p/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		HandleSnippetHistory(w, r, store)
+	})
+	get := func(url string) (int, models.SnippetHistory) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		var history models.SnippetHistory
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w.Code, history
+	}
+
+	status, history := get("/p/" + child.ID + "/history")
+	if status != http.StatusOK || len(history.Lineage) != 2 || history.From != parent.ID || history.Diff == "" {
+		t.Errorf("history = %d %+v, want both revisions and the diff from the parent", status, history)
+	}
+
+	if err := backend.Delete(parent.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"missing parent", "/p/" + child.ID + "/history", http.StatusOK},
+		{"missing from", "/p/" + child.ID + "/history?from=" + parent.ID, http.StatusNotFound},
+		{"missing snippet", "/p/" + parent.ID + "/history", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		status, history := get(test.url)
+		if status != test.status {
+			t.Errorf("%s: status %d, want %d", test.name, status, test.status)
+		}
+		if status == http.StatusOK && (len(history.Lineage) != 1 || history.Lineage[0].ID != child.ID || history.Diff != "") {
+			t.Errorf("%s: history = %+v, want the child alone without a diff", test.name, history)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/AlexandruC0909/playground/internal/batch"
+	"github.com/AlexandruC0909/playground/internal/classroom"
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/similarity"
+	"github.com/go-chi/chi/v5"
+)
+
+// HandleBatchSimilarity compares the programs of the batch job named by the id
+// URL parameter and reports those similar enough to have been copied.
+func HandleBatchSimilarity(w http.ResponseWriter, r *http.Request, queue *batch.Queue) {
+	threshold, err := parseThreshold(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	programs, starterCode, err := queue.Programs(chi.URLParam(r, "id"))
+	if !writeBatchError(w, err) {
+		return
+	}
+
+	submissions := make([]similarity.Submission, len(programs))
+	for i, program := range programs {
+		submissions[i] = similarity.Submission{Name: program.Name, Code: program.Code}
+	}
+	writeJSON(w, similarity.Analyze(submissions, starterCode, threshold))
+}
+
+// HandleRoomSimilarity compares the latest code of the students of a room,
+// leaving out its starter code.
+func HandleRoomSimilarity(w http.ResponseWriter, r *http.Request, rooms *classroom.Rooms) {
+	room, ok := instructorRoom(w, r, rooms)
+	if !ok {
+		return
+	}
+	threshold, err := parseThreshold(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshot := room.Snapshot(true)
+	counts := make(map[string]int)
+	for _, student := range snapshot.Students {
+		counts[student.Name]++
+	}
+	var submissions []similarity.Submission
+	for _, student := range snapshot.Students {
+		// Students may share a name; their IDs tell them apart.
+		name := student.Name
+		if counts[name] > 1 {
+			name = fmt.Sprintf("%s (%s)", name, student.ID)
+		}
+		submissions = append(submissions, similarity.Submission{Name: name, Code: student.Code})
+	}
+	writeJSON(w, similarity.Analyze(submissions, snapshot.StarterCode, threshold))
+}
+
+// parseThreshold returns the threshold query parameter, which defaults to
+// config.SimilarityThreshold.
+func parseThreshold(r *http.Request) (float64, error) {
+	value := r.URL.Query().Get("threshold")
+	if value == "" {
+		return config.SimilarityThreshold, nil
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		return 0, fmt.Errorf("threshold must be a number above 0 and at most 1")
+	}
+	return threshold, nil
+}
@@ -0,0 +1,170 @@
+package imports
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Package is a standard library package as seen by import resolution.
+type Package struct {
+	Name    string   `json:"name"`
+	Exports []string `json:"exports"`
+}
+
+// Index maps the import paths of the standard library to their packages.
+type Index map[string]Package
+
+// Fix formats src after adding the standard library imports it is missing and
+// removing the ones it does not use, like goimports. Missing imports are
+// resolved against index; a package is only chosen if it exports every
+// selector used with its name.
+func Fix(src []byte, index Index) ([]byte, []models.ImportChange, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs := unresolvedRefs(file)
+	var changes []models.ImportChange
+	imported := make(map[string]bool)
+
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		var explicit string
+		if spec.Name != nil {
+			explicit = spec.Name.Name
+		}
+		if explicit == "_" || explicit == "." || importPath == "C" {
+			continue
+		}
+
+		name := explicit
+		if name == "" {
+			name = index.packageName(importPath)
+		}
+		if _, used := refs[name]; used {
+			imported[name] = true
+			continue
+		}
+
+		if astutil.DeleteNamedImport(fset, file, explicit, importPath) {
+			changes = append(changes, models.ImportChange{Action: "remove", Path: importPath, Name: explicit})
+		}
+	}
+
+	var added []string
+	for name, selectors := range refs {
+		if imported[name] {
+			continue
+		}
+		if importPath := index.resolve(name, selectors); importPath != "" {
+			added = append(added, importPath)
+		}
+	}
+	sort.Strings(added)
+	for _, importPath := range added {
+		astutil.AddImport(fset, file, importPath)
+		changes = append(changes, models.ImportChange{Action: "add", Path: importPath})
+	}
+
+	ast.SortImports(fset, file)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, nil, err
+	}
+
+	// Reformat so that import blocks emptied or created above are laid out
+	// the way gofmt would.
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return formatted, changes, nil
+}
+
+// unresolvedRefs returns the identifiers used as the operand of a selector
+// without being declared anywhere in file, each with the selected names.
+// These are the candidate package names.
+func unresolvedRefs(file *ast.File) map[string]map[string]bool {
+	refs := make(map[string]map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || ident.Obj != nil || ident.Name == "_" {
+			return true
+		}
+		if refs[ident.Name] == nil {
+			refs[ident.Name] = make(map[string]bool)
+		}
+		refs[ident.Name][sel.Sel.Name] = true
+		return true
+	})
+	return refs
+}
+
+// packageName returns the name of the package at importPath, guessing it from
+// the path for packages outside the index the way goimports does: the last
+// element that is not a major version, without a "go-" prefix and up to the
+// first character that cannot be in an identifier.
+func (index Index) packageName(importPath string) string {
+	if pkg, ok := index[importPath]; ok {
+		return pkg.Name
+	}
+	name := path.Base(importPath)
+	if strings.HasPrefix(name, "v") && path.Dir(importPath) != "." {
+		if _, err := strconv.Atoi(name[1:]); err == nil {
+			name = path.Base(path.Dir(importPath))
+		}
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexFunc(name, notIdentifier); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func notIdentifier(r rune) bool {
+	return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// resolve returns the import path of the package named name that exports all
+// of selectors. Among several matches the shortest path wins, so math/rand is
+// preferred over crypto/rand and math/rand/v2.
+func (index Index) resolve(name string, selectors map[string]bool) string {
+	var best string
+	for importPath, pkg := range index {
+		if pkg.Name != name || !pkg.exportsAll(selectors) {
+			continue
+		}
+		if best == "" || len(importPath) < len(best) || len(importPath) == len(best) && importPath < best {
+			best = importPath
+		}
+	}
+	return best
+}
+
+func (pkg Package) exportsAll(selectors map[string]bool) bool {
+	for selector := range selectors {
+		if !slices.Contains(pkg.Exports, selector) {
+			return false
+		}
+	}
+	return true
+}
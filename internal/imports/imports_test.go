@@ -0,0 +1,119 @@
+package imports
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+var testIndex = Index{
+	"fmt":          {Name: "fmt", Exports: []string{"Println", "Sprintf"}},
+	"os":           {Name: "os", Exports: []string{"Args", "Stdin"}},
+	"strings":      {Name: "strings", Exports: []string{"ToUpper", "Fields"}},
+	"math/rand":    {Name: "rand", Exports: []string{"Intn", "Read"}},
+	"math/rand/v2": {Name: "rand", Exports: []string{"IntN", "N"}},
+	"crypto/rand":  {Name: "rand", Exports: []string{"Read", "Reader"}},
+}
+
+func TestFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		changes []models.ImportChange
+	}{
+		{
+			name: "add missing",
+			src:  "package main\n\nfunc main() { fmt.Println(strings.ToUpper(\"hi\")) }\n",
+			want: "package main\n\nimport (\n\t\"fmt\"\n\t\"strings\"\n)\n\nfunc main() { fmt.Println(strings.ToUpper(\"hi\")) }\n",
+			changes: []models.ImportChange{
+				{Action: "add", Path: "fmt"},
+				{Action: "add", Path: "strings"},
+			},
+		},
+		{
+			name:    "remove unused",
+			src:     "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println() }\n",
+			want:    "package main\n\nimport (\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println() }\n",
+			changes: []models.ImportChange{{Action: "remove", Path: "os"}},
+		},
+		{
+			name:    "remove named",
+			src:     "package main\n\nimport f \"fmt\"\n\nfunc main() {}\n",
+			want:    "package main\n\nfunc main() {}\n",
+			changes: []models.ImportChange{{Action: "remove", Path: "fmt", Name: "f"}},
+		},
+		{
+			name: "keep used named and blank",
+			src:  "package main\n\nimport (\n\t_ \"embed\"\n\tf \"fmt\"\n)\n\nfunc main() { f.Println() }\n",
+			want: "package main\n\nimport (\n\t_ \"embed\"\n\tf \"fmt\"\n)\n\nfunc main() { f.Println() }\n",
+		},
+		{
+			// The shortest path exporting every selector wins.
+			name:    "shortest match",
+			src:     "package main\n\nfunc main() { _ = rand.Intn(3) }\n",
+			want:    "package main\n\nimport \"math/rand\"\n\nfunc main() { _ = rand.Intn(3) }\n",
+			changes: []models.ImportChange{{Action: "add", Path: "math/rand"}},
+		},
+		{
+			name:    "selectors decide",
+			src:     "package main\n\nfunc main() { rand.Read(nil); _ = rand.Reader }\n",
+			want:    "package main\n\nimport \"crypto/rand\"\n\nfunc main() { rand.Read(nil); _ = rand.Reader }\n",
+			changes: []models.ImportChange{{Action: "add", Path: "crypto/rand"}},
+		},
+		{
+			name: "declared identifiers",
+			src:  "package main\n\ntype T struct{ Println int }\n\nfunc main() {\n\tvar fmt T\n\t_ = fmt.Println\n}\n",
+			want: "package main\n\ntype T struct{ Println int }\n\nfunc main() {\n\tvar fmt T\n\t_ = fmt.Println\n}\n",
+		},
+		{
+			// Packages outside the index are named after their path.
+			name: "keep used outside index",
+			src:  "package main\n\nimport \"gopkg.in/yaml.v3\"\n\nfunc main() { yaml.Marshal(nil) }\n",
+			want: "package main\n\nimport \"gopkg.in/yaml.v3\"\n\nfunc main() { yaml.Marshal(nil) }\n",
+		},
+		{
+			name: "unknown package",
+			src:  "package main\n\nfunc main() { yaml.Marshal(nil) }\n",
+			want: "package main\n\nfunc main() { yaml.Marshal(nil) }\n",
+		},
+	}
+	for _, test := range tests {
+		got, changes, err := Fix([]byte(test.src), testIndex)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s:\n got %q\nwant %q", test.name, got, test.want)
+		}
+		if !reflect.DeepEqual(changes, test.changes) {
+			t.Errorf("%s: changes = %+v, want %+v", test.name, changes, test.changes)
+		}
+	}
+
+	if _, _, err := Fix([]byte("package main\nfunc {"), testIndex); err == nil {
+		t.Error("Fix of invalid code succeeded")
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		importPath string
+		want       string
+	}{
+		{"math/rand/v2", "rand"},
+		{"github.com/go-yaml/yaml", "yaml"},
+		{"gopkg.in/go-playground/validator/v10", "validator"},
+		{"github.com/google/uuid", "uuid"},
+		{"example.com/v2", "example"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"github.com/mattn/go-sqlite3", "sqlite3"},
+	}
+	for _, test := range tests {
+		if got := testIndex.packageName(test.importPath); got != test.want {
+			t.Errorf("packageName(%q) = %q, want %q", test.importPath, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package lessons
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strconv"
+
+	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/runner"
+)
+
+// Evaluate checks a solution to exercise: with its hidden tests if it has
+// any, or else by running it and checking the result.
+func Evaluate(ctx context.Context, executor *docker.Executor, exercise models.Exercise, code string) models.ExerciseResult {
+	if len(exercise.TestFiles) > 0 {
+		return Grade(ctx, executor, exercise, code)
+	}
+	result := runner.Run(ctx, executor, code, exercise.Input, models.RunOptions{})
+	return Check(exercise, code, result)
+}
+
+// Check evaluates the result of running code against exercise. Hints are
+// only returned with failures.
+func Check(exercise models.Exercise, code string, result models.RunResult) models.ExerciseResult {
+	report := models.ExerciseResult{Output: result.Output, Error: result.Error}
+
+	switch {
+	case result.Error != "":
+		report.Failures = append(report.Failures, "The program did not run successfully.")
+	case result.Stderr != "":
+		report.Error = result.Stderr
+		report.Failures = append(report.Failures, "The program wrote to standard error or exited with an error.")
+	case exercise.Output != "" && !runner.MatchOutput(result.Output, exercise.Output):
+		report.Failures = append(report.Failures, "The output is not what was expected.")
+	}
+
+	if result.Error == "" {
+		calls := calledFuncs(code)
+		for _, check := range exercise.Checks {
+			if !passes(check, result.Output, calls) {
+				report.Failures = append(report.Failures, check.Message)
+			}
+		}
+	}
+
+	report.Passed = len(report.Failures) == 0
+	if !report.Passed {
+		report.Hints = exercise.Hints
+	}
+	return report
+}
+
+func validateCheck(check models.ExerciseCheck) error {
+	set := 0
+	for _, field := range []string{check.OutputMatches, check.OutputLacks, check.Calls, check.Avoids} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of outputMatches, outputLacks, calls and avoids must be set")
+	}
+	if check.Message == "" {
+		return fmt.Errorf("message is required")
+	}
+	for _, pattern := range []string{check.OutputMatches, check.OutputLacks} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func passes(check models.ExerciseCheck, output string, calls map[string]bool) bool {
+	switch {
+	case check.OutputMatches != "":
+		return regexp.MustCompile(check.OutputMatches).MatchString(output)
+	case check.OutputLacks != "":
+		return !regexp.MustCompile(check.OutputLacks).MatchString(output)
+	case check.Calls != "":
+		return calls[check.Calls]
+	default:
+		return !calls[check.Avoids]
+	}
+}
+
+// calledFuncs returns the functions code calls, as "path.Func" for package
+// functions, whatever name the package is imported as, and as "name" for
+// functions declared in the program. Code that does not parse calls nothing.
+func calledFuncs(code string) map[string]bool {
+	calls := make(map[string]bool)
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", code, 0)
+	if err != nil {
+		return calls
+	}
+
+	packages := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		packages[name] = importPath
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			calls[fun.Name] = true
+		case *ast.SelectorExpr:
+			if x, ok := fun.X.(*ast.Ident); ok {
+				if importPath, ok := packages[x.Name]; ok {
+					calls[importPath+"."+fun.Sel.Name] = true
+				}
+			}
+		}
+		return true
+	})
+	return calls
+}
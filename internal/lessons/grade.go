@@ -0,0 +1,281 @@
+package lessons
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/utils"
+)
+
+// hiddenTestFile is the name the hidden tests get next to the solution.
+const hiddenTestFile = "exercise_test.go"
+
+// testEvent is an event of the go test -json stream.
+type testEvent struct {
+	Action string
+	Test   string
+	Output string
+}
+
+// sandboxPath matches the directory of the files in go build errors.
+var sandboxPath = regexp.MustCompile(`(?m)^\S*/`)
+
+// Grade runs the hidden tests of exercise against code in the sandbox and
+// scores the solution. Students see each test's result under its configured
+// message, and its output only if the exercise allows it; build errors in
+// the hidden tests are not shown.
+//
+// The tests run in the same process as the solution, which could forge
+// their output. Grading is meant for honest students, not as a security
+// boundary.
+func Grade(ctx context.Context, executor *docker.Executor, exercise models.Exercise, code string) models.ExerciseResult {
+	var report models.ExerciseResult
+	fail := func(message string) models.ExerciseResult {
+		report.Failures = append(report.Failures, message)
+		report.Hints = exercise.Hints
+		return report
+	}
+
+	if err := utils.ValidateAndPrepare(code, models.NewSession()); err != nil {
+		report.Error = err.Error()
+		return fail("The program did not run successfully.")
+	}
+
+	// go test stops the tests themselves; leave as long again to build them.
+	ctx, cancel := context.WithTimeout(ctx, 2*config.TestTimeoutSeconds*time.Second)
+	defer cancel()
+
+	files := make(map[string][]byte)
+	for name, content := range exercise.TestFiles {
+		files[name] = []byte(content)
+	}
+	stdout, stderr, err := executor.Test(ctx, code, files)
+	if err != nil {
+		log.Printf("Failed to run exercise tests: %v\n", err)
+		report.Error = "The tests could not be run, try again later."
+		return fail("The tests could not be run.")
+	}
+
+	results, buildOutput, built := parseTestEvents(stdout)
+	if !built {
+		report.Error = buildErrors(stderr+buildOutput, exercise.TestFiles)
+		return fail("The code does not compile together with the tests.")
+	}
+
+	report.Tests, report.Score = score(exercise, results)
+
+	passScore := exercise.PassScore
+	if passScore == 0 {
+		passScore = 1
+	}
+	// Scores are sums of float weights; allow for rounding.
+	report.Passed = *report.Score >= passScore-1e-9
+
+	calls := calledFuncs(code)
+	for _, check := range exercise.Checks {
+		if !passes(check, "", calls) {
+			report.Failures = append(report.Failures, check.Message)
+			report.Passed = false
+		}
+	}
+
+	if !report.Passed {
+		report.Hints = exercise.Hints
+	}
+	return report
+}
+
+// testRun is the outcome of a top-level test in the event stream.
+type testRun struct {
+	passed bool
+	output strings.Builder
+}
+
+// parseTestEvents returns the top-level tests of a go test -json stream by
+// name, with the output of their subtests. It also returns any build output
+// in the stream, and reports whether the tests were built; older toolchains
+// write build errors to standard error instead.
+func parseTestEvents(stream string) (map[string]*testRun, string, bool) {
+	runs := make(map[string]*testRun)
+	var buildOutput strings.Builder
+	built := true
+
+	scanner := bufio.NewScanner(strings.NewReader(stream))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+
+		switch {
+		case event.Action == "build-output":
+			buildOutput.WriteString(event.Output)
+			continue
+		case event.Action == "build-fail", strings.Contains(event.Output, "[build failed]"):
+			built = false
+			continue
+		case event.Test == "":
+			continue
+		}
+
+		name, _, _ := strings.Cut(event.Test, "/")
+		run, ok := runs[name]
+		if !ok {
+			run = &testRun{}
+			runs[name] = run
+		}
+
+		switch event.Action {
+		case "output":
+			// Keep what the test logged, not the framing lines.
+			line := strings.TrimSpace(event.Output)
+			if !strings.HasPrefix(line, "=== ") && !strings.HasPrefix(line, "--- ") {
+				run.output.WriteString(event.Output)
+			}
+		case "pass", "fail", "skip":
+			if event.Test == name {
+				run.passed = event.Action == "pass"
+			}
+		}
+	}
+	return runs, buildOutput.String(), built
+}
+
+// score reports every configured test, then the others in name order, and
+// returns the fraction of the weight earned. Configured tests that did not
+// run failed.
+func score(exercise models.Exercise, runs map[string]*testRun) ([]models.TestResult, *float64) {
+	var results []models.TestResult
+	var total, earned float64
+
+	add := func(test models.ExerciseTest, run *testRun) {
+		result := models.TestResult{Name: test.Name, Weight: test.Weight}
+		if test.Message != "" {
+			result.Name = test.Message
+		}
+		if result.Weight == 0 {
+			result.Weight = 1
+		}
+		if run != nil {
+			result.Passed = run.passed
+			if test.ShowOutput {
+				result.Output = run.output.String()
+			}
+		}
+
+		total += result.Weight
+		if result.Passed {
+			earned += result.Weight
+		}
+		results = append(results, result)
+	}
+
+	configured := make(map[string]bool)
+	for _, test := range exercise.Tests {
+		configured[test.Name] = true
+		add(test, runs[test.Name])
+	}
+	var others []string
+	for name := range runs {
+		if !configured[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		add(models.ExerciseTest{Name: name}, runs[name])
+	}
+
+	fraction := 0.0
+	if total > 0 {
+		fraction = earned / total
+	}
+	return results, &fraction
+}
+
+// buildErrors returns the build errors of the solution itself, without
+// sandbox paths. Errors in the hidden tests are summarised, since they would
+// reveal the tests.
+func buildErrors(stderr string, testFiles map[string]string) string {
+	var lines []string
+	hidden := false
+	for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+		line = sandboxPath.ReplaceAllString(line, "")
+		switch {
+		case strings.HasPrefix(line, "#") || strings.HasPrefix(line, "FAIL"):
+			continue
+		case isHiddenFileLine(line, testFiles):
+			hidden = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if hidden {
+		lines = append(lines, "The tests use names or signatures this code does not define; check what the exercise asks for.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isHiddenFileLine(line string, testFiles map[string]string) bool {
+	for name := range testFiles {
+		if strings.HasPrefix(line, name+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTests parses the hidden test file of an exercise, checking that it
+// belongs to package main and declares every configured test.
+func loadTests(exercise *models.Exercise, source string) error {
+	file, err := parser.ParseFile(token.NewFileSet(), hiddenTestFile, source, 0)
+	if err != nil {
+		return fmt.Errorf("invalid tests: %v", err)
+	}
+	if file.Name.Name != "main" {
+		return fmt.Errorf("tests must be in package main, not %s", file.Name.Name)
+	}
+
+	declared := make(map[string]bool)
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "Test") {
+			declared[fn.Name.Name] = true
+		}
+	}
+	for _, test := range exercise.Tests {
+		if !declared[test.Name] {
+			return fmt.Errorf("test %s is configured but not declared", test.Name)
+		}
+		if test.Weight < 0 {
+			return fmt.Errorf("test %s has a negative weight", test.Name)
+		}
+	}
+	if exercise.PassScore < 0 || exercise.PassScore > 1 {
+		return fmt.Errorf("passScore must be between 0 and 1")
+	}
+	if exercise.Input != "" || exercise.Output != "" {
+		return fmt.Errorf("exercises graded by tests cannot set input or output")
+	}
+	for _, check := range exercise.Checks {
+		if check.OutputMatches != "" || check.OutputLacks != "" {
+			return fmt.Errorf("exercises graded by tests cannot check the output")
+		}
+	}
+
+	exercise.TestFiles = map[string]string{hiddenTestFile: source}
+	return nil
+}
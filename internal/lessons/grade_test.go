@@ -0,0 +1,173 @@
+package lessons
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// The streams in testdata were written by go test -json, with workspace
+// paths as in the sandbox, for hidden tests of a reverse exercise run against
+// a solution reversing bytes, one that does not compile and one without a
+// reverse function.
+
+func TestParseTestEvents(t *testing.T) {
+	tests := []struct {
+		file        string
+		built       bool
+		passed      map[string]bool
+		output      map[string]string
+		buildOutput string
+	}{
+		{
+			file:   "test_events.json",
+			built:  true,
+			passed: map[string]bool{"TestReverseASCII": true, "TestReverseUnicode": false},
+			output: map[string]string{
+				"TestReverseASCII":   "    exercise_test.go:6: checking abc\n",
+				"TestReverseUnicode": "    exercise_test.go:15: reverse(héllo) = \"oll\\xa9\\xc3h\"\n",
+			},
+		},
+		{
+			file:        "build_failed.json",
+			built:       false,
+			passed:      map[string]bool{},
+			buildOutput: "/tmp/work-1/main.go:3:40: invalid operation",
+		},
+		{
+			file:        "tests_build_failed.json",
+			built:       false,
+			passed:      map[string]bool{},
+			buildOutput: "/tmp/work-1/exercise_test.go:7:12: undefined: reverse",
+		},
+	}
+	for _, test := range tests {
+		stream, err := os.ReadFile("testdata/" + test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		runs, buildOutput, built := parseTestEvents(string(stream))
+		if built != test.built {
+			t.Errorf("%s: built = %v, want %v", test.file, built, test.built)
+		}
+		if !strings.Contains(buildOutput, test.buildOutput) {
+			t.Errorf("%s: build output %q lacks %q", test.file, buildOutput, test.buildOutput)
+		}
+		passed := make(map[string]bool)
+		for name, run := range runs {
+			passed[name] = run.passed
+			if want := test.output[name]; run.output.String() != want {
+				t.Errorf("%s: output of %s = %q, want %q", test.file, name, run.output.String(), want)
+			}
+		}
+		if !reflect.DeepEqual(passed, test.passed) {
+			t.Errorf("%s: passed = %v, want %v", test.file, passed, test.passed)
+		}
+	}
+}
+
+func TestParseTestEventsSkipsNoise(t *testing.T) {
+	stream := "not json\n" +
+		`{"Action":"run","Test":"TestA"}` + "\n" +
+		`{"Action":"skip","Test":"TestA"}` + "\n"
+	runs, _, built := parseTestEvents(stream)
+	if !built || len(runs) != 1 || runs["TestA"].passed {
+		t.Errorf("got %d runs, built %v; want TestA skipped and not passed", len(runs), built)
+	}
+}
+
+func TestScore(t *testing.T) {
+	run := func(passed bool, output string) *testRun {
+		r := &testRun{passed: passed}
+		r.output.WriteString(output)
+		return r
+	}
+	runs := map[string]*testRun{
+		"TestA":     run(true, "a log\n"),
+		"TestB":     run(false, "b failed\n"),
+		"TestExtra": run(true, ""),
+	}
+	exercise := models.Exercise{Tests: []models.ExerciseTest{
+		{Name: "TestA", Weight: 2, Message: "Handles A", ShowOutput: true},
+		{Name: "TestB"},
+		{Name: "TestMissing", Weight: 1},
+	}}
+
+	results, fraction := score(exercise, runs)
+	want := []models.TestResult{
+		{Name: "Handles A", Passed: true, Weight: 2, Output: "a log\n"},
+		{Name: "TestB", Weight: 1},
+		{Name: "TestMissing", Weight: 1},
+		{Name: "TestExtra", Passed: true, Weight: 1},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results:\n got %+v\nwant %+v", results, want)
+	}
+	if *fraction != 3.0/5 {
+		t.Errorf("score = %v, want %v", *fraction, 3.0/5)
+	}
+
+	if _, fraction := score(models.Exercise{}, nil); *fraction != 0 {
+		t.Errorf("score without tests = %v, want 0", *fraction)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	testFiles := map[string]string{hiddenTestFile: ""}
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			"solution",
+			"# command-line-arguments [command-line-arguments.test]\n/tmp/work-1/main.go:3:40: invalid operation\n",
+			"main.go:3:40: invalid operation",
+		},
+		{
+			"hidden tests",
+			"# command-line-arguments\n/tmp/work-1/exercise_test.go:7:12: undefined: reverse\nFAIL\tcommand-line-arguments [build failed]\n",
+			"The tests use names or signatures this code does not define; check what the exercise asks for.",
+		},
+	}
+	for _, test := range tests {
+		if got := buildErrors(test.output, testFiles); got != test.want {
+			t.Errorf("%s: buildErrors = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLoadTests(t *testing.T) {
+	const source = "package main\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n"
+	tests := []struct {
+		name     string
+		source   string
+		exercise models.Exercise
+		wantErr  string
+	}{
+		{"valid", source, models.Exercise{Tests: []models.ExerciseTest{{Name: "TestA"}}, PassScore: 0.5}, ""},
+		{"syntax error", "package main\nfunc", models.Exercise{}, "invalid tests"},
+		{"other package", "package other\n", models.Exercise{}, "package main"},
+		{"undeclared", source, models.Exercise{Tests: []models.ExerciseTest{{Name: "TestB"}}}, "not declared"},
+		{"negative weight", source, models.Exercise{Tests: []models.ExerciseTest{{Name: "TestA", Weight: -1}}}, "negative weight"},
+		{"pass score", source, models.Exercise{PassScore: 2}, "passScore"},
+		{"output", source, models.Exercise{Output: "x"}, "input or output"},
+		{"output check", source, models.Exercise{Checks: []models.ExerciseCheck{{OutputMatches: "x"}}}, "check the output"},
+	}
+	for _, test := range tests {
+		exercise := test.exercise
+		err := loadTests(&exercise, test.source)
+		switch {
+		case test.wantErr == "" && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case test.wantErr == "" && exercise.TestFiles[hiddenTestFile] != test.source:
+			t.Errorf("%s: tests not stored as %s", test.name, hiddenTestFile)
+		case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+			t.Errorf("%s: error %v, want one containing %q", test.name, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package lessons
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/yuin/goldmark"
+)
+
+// defaultCode is the editor content of pages without starter code.
+const defaultCode = "package main\n\nfunc main() {\n}\n"
+
+// orderPrefix is the numeric prefix that orders lessons and pages on disk;
+// it is not part of their slugs.
+var orderPrefix = regexp.MustCompile(`^[0-9]+-`)
+
+// Catalog holds the lessons. The zero value is an empty catalog.
+type Catalog struct {
+	lessons []models.Lesson
+}
+
+// Load reads the lessons in dir. Every subdirectory is a lesson, ordered by
+// name, made of:
+//
+//	index.md          the lesson's "# Title" and description
+//	NN-page.md        a page, starting with its "# Title"
+//	NN-page.go        the page's starter code (optional)
+//	NN-page.json      the page's exercise, a models.Exercise (optional)
+//	NN-page_test.go   hidden tests grading the exercise (optional)
+//
+// Starter code and tests start with a //go:build ignore constraint, like the
+// bundled examples, which is removed. Markdown is rendered without raw HTML.
+func Load(dir string) (*Catalog, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lessons: %v", err)
+	}
+
+	c := &Catalog{}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		lesson, err := loadLesson(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("invalid lesson %s: %v", entry.Name(), err)
+		}
+		if seen[lesson.Slug] {
+			return nil, fmt.Errorf("duplicate lesson %s", lesson.Slug)
+		}
+		seen[lesson.Slug] = true
+		c.lessons = append(c.lessons, lesson)
+	}
+	return c, nil
+}
+
+// Lessons returns every lesson in order.
+func (c *Catalog) Lessons() []models.Lesson {
+	return c.lessons
+}
+
+// Page returns the page called pageSlug of the lesson called lessonSlug, with
+// the lesson.
+func (c *Catalog) Page(lessonSlug, pageSlug string) (models.Lesson, models.LessonPage, bool) {
+	for _, lesson := range c.lessons {
+		if lesson.Slug != lessonSlug {
+			continue
+		}
+		for _, page := range lesson.Pages {
+			if page.Slug == pageSlug {
+				return lesson, page, true
+			}
+		}
+	}
+	return models.Lesson{}, models.LessonPage{}, false
+}
+
+// Neighbours returns the paths of the pages before and after the given one,
+// continuing into the previous and next lessons. They are empty at either
+// end of the course.
+func (c *Catalog) Neighbours(lessonSlug, pageSlug string) (prev, next string) {
+	var paths []string
+	current := -1
+	for _, lesson := range c.lessons {
+		for _, page := range lesson.Pages {
+			if lesson.Slug == lessonSlug && page.Slug == pageSlug {
+				current = len(paths)
+			}
+			paths = append(paths, Path(lesson.Slug, page.Slug))
+		}
+	}
+	if current < 0 {
+		return "", ""
+	}
+	if current > 0 {
+		prev = paths[current-1]
+	}
+	if current < len(paths)-1 {
+		next = paths[current+1]
+	}
+	return prev, next
+}
+
+// Path returns the URL path of a lesson page.
+func Path(lessonSlug, pageSlug string) string {
+	return "/learn/" + lessonSlug + "/" + pageSlug
+}
+
+func loadLesson(dir string) (models.Lesson, error) {
+	lesson := models.Lesson{Slug: slug(filepath.Base(dir))}
+
+	title, description, err := readMarkdown(filepath.Join(dir, "index.md"))
+	if err != nil {
+		return lesson, err
+	}
+	lesson.Title = title
+	lesson.Description = description
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return lesson, fmt.Errorf("failed to list pages: %v", err)
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if filepath.Base(name) == "index.md" {
+			continue
+		}
+		page, err := loadPage(strings.TrimSuffix(name, ".md"))
+		if err != nil {
+			return lesson, fmt.Errorf("page %s: %v", filepath.Base(name), err)
+		}
+		if seen[page.Slug] {
+			return lesson, fmt.Errorf("duplicate page %s", page.Slug)
+		}
+		seen[page.Slug] = true
+		lesson.Pages = append(lesson.Pages, page)
+	}
+	if len(lesson.Pages) == 0 {
+		return lesson, fmt.Errorf("no pages")
+	}
+	return lesson, nil
+}
+
+// loadPage reads the files of the page whose paths start with base.
+func loadPage(base string) (models.LessonPage, error) {
+	page := models.LessonPage{Slug: slug(filepath.Base(base)), Code: defaultCode}
+
+	title, content, err := readMarkdown(base + ".md")
+	if err != nil {
+		return page, err
+	}
+	page.Title = title
+	page.Content = content
+
+	code, err := os.ReadFile(base + ".go")
+	switch {
+	case err == nil:
+		if len(code) > config.MaxCodeSize {
+			return page, fmt.Errorf("starter code too large: %d bytes", len(code))
+		}
+		page.Code = stripBuildConstraint(string(code))
+	case !os.IsNotExist(err):
+		return page, fmt.Errorf("failed to read starter code: %v", err)
+	}
+
+	data, err := os.ReadFile(base + ".json")
+	switch {
+	case err == nil:
+		exercise, err := parseExercise(data)
+		if err != nil {
+			return page, err
+		}
+		page.Exercise = &exercise
+	case !os.IsNotExist(err):
+		return page, fmt.Errorf("failed to read exercise: %v", err)
+	}
+
+	tests, err := os.ReadFile(base + "_test.go")
+	switch {
+	case err == nil:
+		if page.Exercise == nil {
+			page.Exercise = &models.Exercise{}
+		}
+		if err := loadTests(page.Exercise, stripBuildConstraint(string(tests))); err != nil {
+			return page, err
+		}
+	case !os.IsNotExist(err):
+		return page, fmt.Errorf("failed to read tests: %v", err)
+	case page.Exercise != nil && len(page.Exercise.Tests) > 0:
+		return page, fmt.Errorf("tests are configured but %s is missing", filepath.Base(base)+"_test.go")
+	}
+	return page, nil
+}
+
+// readMarkdown returns the text of the "# Title" heading starting the file
+// and the HTML of the rest.
+func readMarkdown(path string) (string, template.HTML, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+	}
+
+	heading, body, _ := strings.Cut(string(source), "\n")
+	if !strings.HasPrefix(heading, "# ") {
+		return "", "", fmt.Errorf("%s must start with a \"# Title\" heading", filepath.Base(path))
+	}
+
+	var html bytes.Buffer
+	if err := goldmark.Convert([]byte(body), &html); err != nil {
+		return "", "", fmt.Errorf("failed to render %s: %v", filepath.Base(path), err)
+	}
+	// goldmark leaves out raw HTML, so its output is safe to include as is.
+	return strings.TrimSpace(strings.TrimPrefix(heading, "# ")), template.HTML(html.String()), nil
+}
+
+func parseExercise(data []byte) (models.Exercise, error) {
+	var exercise models.Exercise
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&exercise); err != nil {
+		return exercise, fmt.Errorf("invalid exercise: %v", err)
+	}
+
+	for i, check := range exercise.Checks {
+		if err := validateCheck(check); err != nil {
+			return exercise, fmt.Errorf("invalid exercise check %d: %v", i+1, err)
+		}
+	}
+	return exercise, nil
+}
+
+func slug(name string) string {
+	return orderPrefix.ReplaceAllString(name, "")
+}
+
+// stripBuildConstraint removes a leading //go:build line and the blank lines
+// after it.
+func stripBuildConstraint(code string) string {
+	if !strings.HasPrefix(code, "//go:build ") {
+		return code
+	}
+	_, rest, _ := strings.Cut(code, "\n")
+	return strings.TrimLeft(rest, "\n")
+}
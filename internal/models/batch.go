@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Batch job statuses.
+const (
+	BatchQueued    = "queued"
+	BatchRunning   = "running"
+	BatchDone      = "done"
+	BatchCancelled = "cancelled"
+)
+
+// Batch program statuses. A program passes if it ran and, when an output is
+// expected, printed it; it fails otherwise. Programs left when their job is
+// cancelled are skipped.
+const (
+	ProgramPending = "pending"
+	ProgramPassed  = "passed"
+	ProgramFailed  = "failed"
+	ProgramSkipped = "skipped"
+)
+
+// BatchProgram is a program to run in a batch job, with the standard input to
+// feed it and the output expected from it, if any. Name identifies it in the
+// report and defaults to its position, starting from 1.
+type BatchProgram struct {
+	Name   string `json:"name,omitempty"`
+	Code   string `json:"code"`
+	Input  string `json:"input,omitempty"`
+	Output string `json:"output,omitempty"`
+}
+
+// BatchRequest submits programs to run as one job. StarterCode, the code the
+// programs were written from, if any, is left out of similarity analysis.
+type BatchRequest struct {
+	Programs    []BatchProgram `json:"programs"`
+	StarterCode string         `json:"starterCode,omitempty"`
+}
+
+// BatchJob reports the progress of a batch job. Results, in the order the
+// programs were submitted, are only included in reports.
+type BatchJob struct {
+	ID         string        `json:"id"`
+	Status     string        `json:"status"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	FinishedAt *time.Time    `json:"finishedAt,omitempty"`
+	Total      int           `json:"total"`
+	Completed  int           `json:"completed"`
+	Passed     int           `json:"passed"`
+	Failed     int           `json:"failed"`
+	Results    []BatchResult `json:"results,omitempty"`
+}
+
+// BatchResult is the outcome of a program of a batch job. Error is set if it
+// could not be validated, compiled or run; Output and Stderr are truncated to
+// config.MaxBatchOutputSize.
+type BatchResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Output     string `json:"output"`
+	Stderr     string `json:"stderr,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
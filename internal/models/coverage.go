@@ -0,0 +1,21 @@
+package models
+
+// CoverageResult holds per-line execution counts for the submitted files.
+type CoverageResult struct {
+	Mode  string         `json:"mode"`
+	Files []FileCoverage `json:"files"`
+}
+
+// FileCoverage lists the execution count of every line of a file that
+// contains statements. Lines without statements are omitted.
+type FileCoverage struct {
+	Name       string         `json:"name"`
+	Lines      []LineCoverage `json:"lines"`
+	Statements int            `json:"statements"`
+	Covered    int            `json:"covered"`
+}
+
+type LineCoverage struct {
+	Line  int   `json:"line"`
+	Count int64 `json:"count"`
+}
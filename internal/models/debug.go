@@ -0,0 +1,70 @@
+package models
+
+// DebugRequest starts a debug session. Breakpoints are line numbers in the
+// submitted code that are set before the program starts.
+type DebugRequest struct {
+	Code        string `json:"code"`
+	Breakpoints []int  `json:"breakpoints,omitempty"`
+}
+
+// DebugResponse identifies a debug session. DebugToken is sent in the
+// X-Debug-Token header of the /debug/* requests that control it.
+type DebugResponse struct {
+	SessionID  uint64 `json:"sessionId"`
+	DebugToken string `json:"debugToken"`
+}
+
+// DebugCommand moves a stopped program: "continue", "next", "step",
+// "stepOut" or "halt". GoroutineID selects the goroutine to step, 0 for the
+// current one.
+type DebugCommand struct {
+	Command     string `json:"command"`
+	GoroutineID int64  `json:"goroutineId,omitempty"`
+}
+
+type BreakpointRequest struct {
+	Line int `json:"line"`
+}
+
+type DebugBreakpoint struct {
+	ID   int `json:"id"`
+	Line int `json:"line"`
+}
+
+// DebugState is the state of the debugged program after a command.
+type DebugState struct {
+	Running     bool           `json:"running"`
+	Exited      bool           `json:"exited"`
+	ExitStatus  int            `json:"exitStatus"`
+	GoroutineID int64          `json:"goroutineId,omitempty"`
+	Location    *DebugLocation `json:"location,omitempty"`
+	Breakpoint  int            `json:"breakpoint,omitempty"`
+}
+
+type DebugLocation struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	// User is true when the location is in the submitted program.
+	User bool `json:"user"`
+}
+
+type DebugGoroutine struct {
+	ID              int64          `json:"id"`
+	CurrentLocation *DebugLocation `json:"currentLocation"`
+	UserLocation    *DebugLocation `json:"userLocation,omitempty"`
+	StartLocation   *DebugLocation `json:"startLocation,omitempty"`
+}
+
+type DebugFrame struct {
+	Index    int           `json:"index"`
+	Location DebugLocation `json:"location"`
+}
+
+type DebugVariable struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Value    string          `json:"value"`
+	Argument bool            `json:"argument,omitempty"`
+	Children []DebugVariable `json:"children,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// Example is a bundled program that can be opened in the editor by name.
+// Input is the standard input it expects, one line per prompt, and Output
+// its expected standard output when run with that input; Output is empty for
+// programs whose output varies between runs.
+type Example struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Category    string `json:"category"`
+	Description string `json:"description,omitempty"`
+	Order       int    `json:"-"`
+	Input       string `json:"input,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Code        string `json:"code"`
+}
+
+// ExampleCategory groups the examples listed together in the menu.
+type ExampleCategory struct {
+	Name     string
+	Examples []Example
+}
@@ -0,0 +1,8 @@
+package models
+
+// ExportRequest is the body of /export. Files holds extra files, keyed by
+// name, packaged next to main.go.
+type ExportRequest struct {
+	Code  string            `json:"code"`
+	Files map[string]string `json:"files,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+// FormatRequest is the body of /save. FixImports additionally adds missing
+// standard library imports and removes unused ones.
+type FormatRequest struct {
+	Code       string `json:"code"`
+	FixImports bool   `json:"fixImports,omitempty"`
+}
+
+type FormatResponse struct {
+	Code    string         `json:"code"`
+	Changes []ImportChange `json:"changes,omitempty"`
+}
+
+// ImportChange describes an import added or removed while formatting.
+type ImportChange struct {
+	Action string `json:"action"`
+	Path   string `json:"path"`
+	Name   string `json:"name,omitempty"`
+}
+
+// SourceError is a compile error positioned in the submitted code. Kind is
+// "syntax" or "type".
+type SourceError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// CheckResponse lists the errors found by /check, in source order.
+type CheckResponse struct {
+	Errors []SourceError `json:"errors"`
+}
+
+// FormatErrorResponse is returned by /save when the code does not parse.
+// Partial, if set, is the code with every declaration that parsed cleanly
+// formatted and the rest left untouched.
+type FormatErrorResponse struct {
+	Error   string        `json:"error"`
+	Errors  []SourceError `json:"errors"`
+	Partial string        `json:"partial,omitempty"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import "html/template"
+
+// Lesson is a sequence of pages teaching one topic.
+type Lesson struct {
+	Slug        string
+	Title       string
+	Description template.HTML
+	Pages       []LessonPage
+}
+
+// LessonPage is one page of a lesson: text rendered from Markdown, the code
+// the editor starts with and, optionally, an exercise checked on the server.
+type LessonPage struct {
+	Slug     string
+	Title    string
+	Content  template.HTML
+	Code     string
+	Exercise *Exercise
+}
+
+// Exercise defines how a solution to a lesson page is checked. It is never
+// sent to the browser.
+type Exercise struct {
+	// Input is fed to the program's standard input.
+	Input string `json:"input,omitempty"`
+	// Output, if set, is the output the program must print.
+	Output string `json:"output,omitempty"`
+	// Checks are further hidden requirements on the code or its output.
+	Checks []ExerciseCheck `json:"checks,omitempty"`
+	// Hints are shown one at a time after failed attempts.
+	Hints []string `json:"hints,omitempty"`
+	// Tests configures the hidden tests in TestFiles. Instead of running
+	// the program, a solution is graded by running them against it.
+	Tests []ExerciseTest `json:"tests,omitempty"`
+	// PassScore is the fraction of the test weight a solution must earn to
+	// pass; 0 means all of it.
+	PassScore float64 `json:"passScore,omitempty"`
+	// TestFiles holds the hidden _test.go files, keyed by name.
+	TestFiles map[string]string `json:"-"`
+}
+
+// ExerciseTest configures how a hidden test is scored and reported. Tests
+// not configured weigh 1 and are reported by name without output.
+type ExerciseTest struct {
+	Name string `json:"name"`
+	// Weight defaults to 1.
+	Weight float64 `json:"weight,omitempty"`
+	// Message is shown in place of the test's name.
+	Message string `json:"message,omitempty"`
+	// ShowOutput reveals the test's log and failure output to students.
+	ShowOutput bool `json:"showOutput,omitempty"`
+}
+
+// ExerciseCheck is a hidden requirement of an exercise. Exactly one of
+// OutputMatches, OutputLacks, Calls and Avoids is set. Message is what the
+// student sees when it fails.
+type ExerciseCheck struct {
+	// OutputMatches is a regular expression the output must match.
+	OutputMatches string `json:"outputMatches,omitempty"`
+	// OutputLacks is a regular expression the output must not match.
+	OutputLacks string `json:"outputLacks,omitempty"`
+	// Calls is a function the code must call, as "importpath.Func" for a
+	// package function, like "math/rand.Intn", or "name" for one declared in
+	// the program.
+	Calls string `json:"calls,omitempty"`
+	// Avoids is a function, in the same form, the code must not call.
+	Avoids  string `json:"avoids,omitempty"`
+	Message string `json:"message"`
+}
+
+// ExerciseRequest is the body of an exercise check.
+type ExerciseRequest struct {
+	Code string `json:"code"`
+}
+
+// ExerciseResult reports whether a solution passed. Failures holds the
+// messages of the requirements it missed and Hints the exercise's hints.
+// Solutions graded by hidden tests also get a Score, the fraction of the
+// test weight earned, and the result of each test.
+type ExerciseResult struct {
+	Passed   bool         `json:"passed"`
+	Output   string       `json:"output"`
+	Error    string       `json:"error,omitempty"`
+	Failures []string     `json:"failures,omitempty"`
+	Hints    []string     `json:"hints,omitempty"`
+	Score    *float64     `json:"score,omitempty"`
+	Tests    []TestResult `json:"tests,omitempty"`
+}
+
+// TestResult is the outcome of a hidden test as shown to the student.
+type TestResult struct {
+	Name   string  `json:"name"`
+	Passed bool    `json:"passed"`
+	Weight float64 `json:"weight"`
+	Output string  `json:"output,omitempty"`
+}
+
+// LearnPage is the data rendered into lesson.html.
+type LearnPage struct {
+	Lessons []Lesson
+	Lesson  Lesson
+	Page    LessonPage
+	// Prev and Next link to the neighbouring pages, across lessons.
+	Prev, Next string
+}
@@ -0,0 +1,35 @@
+package models
+
+// ProfileResult holds the profiles captured while a program ran.
+type ProfileResult struct {
+	CPU  *ProfileData `json:"cpu,omitempty"`
+	Heap *ProfileData `json:"heap,omitempty"`
+}
+
+// ProfileData is a single pprof profile together with precomputed views of it.
+// Pprof is the raw gzipped protobuf, suitable for `go tool pprof`; it is left
+// out, with PprofOmitted set, if larger than config.MaxProfileSize.
+type ProfileData struct {
+	Pprof        []byte         `json:"pprof,omitempty"`
+	PprofOmitted bool           `json:"pprofOmitted,omitempty"`
+	SampleType   string         `json:"sampleType"`
+	Unit         string         `json:"unit"`
+	Total        int64          `json:"total"`
+	Top          []ProfileEntry `json:"top"`
+	FlameGraph   *FlameNode     `json:"flameGraph"`
+}
+
+// ProfileEntry is one row of a `pprof -top` style table.
+type ProfileEntry struct {
+	Function string `json:"function"`
+	File     string `json:"file,omitempty"`
+	Flat     int64  `json:"flat"`
+	Cum      int64  `json:"cum"`
+}
+
+// FlameNode is a node of a flame graph; Value includes all children.
+type FlameNode struct {
+	Name     string       `json:"name"`
+	Value    int64        `json:"value"`
+	Children []*FlameNode `json:"children,omitempty"`
+}
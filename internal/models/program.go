@@ -1,10 +1,16 @@
 package models
 
+import "time"
+
 type ProgramOutput struct {
-	Output          string `json:"output,omitempty"`
-	Error           string `json:"error,omitempty"`
-	WaitingForInput bool   `json:"waitingForInput"`
-	Done            bool   `json:"done"`
+	Output          string          `json:"output,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	WaitingForInput bool            `json:"waitingForInput"`
+	Done            bool            `json:"done"`
+	Races           []RaceReport    `json:"races,omitempty"`
+	Profile         *ProfileResult  `json:"profile,omitempty"`
+	Trace           *TraceResult    `json:"trace,omitempty"`
+	Coverage        *CoverageResult `json:"coverage,omitempty"`
 }
 
 type InputRequest struct {
@@ -17,10 +23,34 @@ type InputOperation struct {
 	Package string
 }
 
+// RunOptions selects how a program is built and run inside the sandbox.
+type RunOptions struct {
+	Race    bool `json:"race,omitempty"`
+	Profile bool `json:"profile,omitempty"`
+	Trace   bool `json:"trace,omitempty"`
+	Cover   bool `json:"cover,omitempty"`
+	// Dir is the sandbox directory the program is built and run in, as
+	// created by Executor.Workspace; empty means the executor's work
+	// directory. It is set by the server, never by clients.
+	Dir string `json:"-"`
+}
+
 type CodeRequest struct {
 	Code string `json:"code"`
+	RunOptions
 }
 
 type SessionResponse struct {
 	SessionID uint64 `json:"sessionId"`
 }
+
+// RunResult is the outcome of running a program to completion without a
+// client attached. Error is set if the program could not be validated,
+// compiled or run; Stderr holds what the program itself wrote to standard
+// error, including the exit status reported by go run.
+type RunResult struct {
+	Output   string        `json:"output"`
+	Stderr   string        `json:"stderr,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
@@ -0,0 +1,32 @@
+package models
+
+// RaceReport is a single "WARNING: DATA RACE" block reported by the race detector.
+type RaceReport struct {
+	Accesses   []RaceAccess    `json:"accesses"`
+	Goroutines []RaceGoroutine `json:"goroutines,omitempty"`
+}
+
+// RaceAccess is one of the conflicting memory accesses of a race.
+type RaceAccess struct {
+	Kind      string       `json:"kind"`
+	Address   string       `json:"address"`
+	Goroutine string       `json:"goroutine"`
+	Previous  bool         `json:"previous"`
+	Stack     []StackFrame `json:"stack"`
+}
+
+// RaceGoroutine describes where a goroutine taking part in a race was created.
+type RaceGoroutine struct {
+	ID        string       `json:"id"`
+	State     string       `json:"state"`
+	CreatedAt []StackFrame `json:"createdAt"`
+}
+
+// StackFrame is a single frame of a stack trace. Source is only set for frames
+// that point into the submitted program.
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Source   string `json:"source,omitempty"`
+}
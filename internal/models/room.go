@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+// Room event types. A "room" event carries the room as its subscriber may see
+// it and is sent first; "student" events carry a student whose code or run
+// changed and are only sent to the instructor; "starter" and "broadcast"
+// events carry code the instructor pushed; "closed" ends the stream.
+const (
+	RoomEventRoom      = "room"
+	RoomEventStudent   = "student"
+	RoomEventStarter   = "starter"
+	RoomEventBroadcast = "broadcast"
+	RoomEventClosed    = "closed"
+)
+
+// Room is a classroom room. Students only see its starter code and
+// broadcasts; Students is filled in for the instructor.
+type Room struct {
+	Code        string          `json:"code"`
+	StarterCode string          `json:"starterCode"`
+	Broadcasts  []RoomBroadcast `json:"broadcasts"`
+	Students    []RoomStudent   `json:"students,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// RoomStudent is a student of a room with their latest code and run.
+type RoomStudent struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Code      string    `json:"code"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Run       *RoomRun  `json:"run,omitempty"`
+}
+
+// RoomRun is the outcome of a student's run, as streamed to the student.
+// Output is truncated to config.MaxRoomOutputSize.
+type RoomRun struct {
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Output     string     `json:"output"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// RoomBroadcast is a snippet the instructor sent to every student.
+type RoomBroadcast struct {
+	Code    string    `json:"code"`
+	Message string    `json:"message,omitempty"`
+	SentAt  time.Time `json:"sentAt"`
+}
+
+// RoomEvent is an event of a room's stream.
+type RoomEvent struct {
+	Type      string         `json:"type"`
+	Room      *Room          `json:"room,omitempty"`
+	Student   *RoomStudent   `json:"student,omitempty"`
+	Code      string         `json:"code,omitempty"`
+	Broadcast *RoomBroadcast `json:"broadcast,omitempty"`
+}
+
+// CreateRoomRequest creates a room, optionally with starter code.
+type CreateRoomRequest struct {
+	StarterCode string `json:"starterCode,omitempty"`
+}
+
+// CreateRoomResponse returns a new room's code and the token, sent in the
+// X-Instructor-Token header, that lets its creator run it.
+type CreateRoomResponse struct {
+	Code            string `json:"code"`
+	InstructorToken string `json:"instructorToken"`
+	URL             string `json:"url"`
+	DashboardURL    string `json:"dashboardUrl"`
+}
+
+// JoinRoomRequest joins a room under Name.
+type JoinRoomRequest struct {
+	Name string `json:"name"`
+}
+
+// JoinRoomResponse identifies a student who joined a room. StudentToken is
+// sent in the X-Student-Token header of their requests.
+type JoinRoomResponse struct {
+	StudentID    string `json:"studentId"`
+	StudentToken string `json:"studentToken"`
+	StarterCode  string `json:"starterCode"`
+}
+
+// RoomCodeRequest carries code sent to a room: a student's latest code,
+// new starter code or a broadcast with its message.
+type RoomCodeRequest struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// RoomPage is the data rendered into the classroom templates.
+type RoomPage struct {
+	Code        string
+	StarterCode string
+}
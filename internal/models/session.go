@@ -8,6 +8,10 @@ type ProgramSession struct {
 	Done             chan struct{}
 	Cleanup          sync.Once
 	DetectedInputOps []InputOperation
+	Code             string
+	// Observer, if set, is called with each output as the run produces it,
+	// whether or not a client streams it.
+	Observer func(ProgramOutput)
 }
 
 func NewSession() *ProgramSession {
@@ -0,0 +1,32 @@
+package models
+
+// SimilarityReport lists the pairs of programs at least Threshold similar,
+// most similar first, and the clusters they form. Compared counts the
+// programs compared; Skipped lists the others.
+type SimilarityReport struct {
+	Threshold float64             `json:"threshold"`
+	Compared  int                 `json:"compared"`
+	Skipped   []SimilaritySkip    `json:"skipped,omitempty"`
+	Pairs     []SimilarityPair    `json:"pairs"`
+	Clusters  []SimilarityCluster `json:"clusters"`
+}
+
+// SimilarityPair is a pair of similar programs. Score goes from 0 to 1.
+type SimilarityPair struct {
+	A     string  `json:"a"`
+	B     string  `json:"b"`
+	Score float64 `json:"score"`
+}
+
+// SimilarityCluster is a group of programs linked by similar pairs, with the
+// highest score among them.
+type SimilarityCluster struct {
+	Members []string `json:"members"`
+	Score   float64  `json:"score"`
+}
+
+// SimilaritySkip is a program left out of the comparison, and why.
+type SimilaritySkip struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
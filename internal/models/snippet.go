@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Snippet is a revision of a shared program, addressed by a random ID, or by
+// a hash of its code and parent revision if it was shared read-only.
+// Revisions are immutable except to holders of the edit token whose hash is
+// EditTokenHash. Checksum is the hex SHA-256 of Code,
+// verified whenever the snippet is read back.
+//
+// Encrypted snippets were encrypted by the client with a key the server never
+// sees; their Code is the base64 of the AES-GCM nonce followed by the
+// ciphertext. Snippets past ExpiresAt are treated as deleted, as are
+// snippets opened MaxViews times if it is set.
+type Snippet struct {
+	ID            string     `json:"id"`
+	Parent        string     `json:"parent,omitempty"`
+	Code          string     `json:"code"`
+	Encrypted     bool       `json:"encrypted,omitempty"`
+	Checksum      string     `json:"checksum"`
+	EditTokenHash string     `json:"editTokenHash,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	MaxViews      int        `json:"maxViews,omitempty"`
+	Views         int        `json:"views,omitempty"`
+}
+
+// ShareRequest shares Code, as a new revision of Parent if set. ExpiresIn
+// is the lifetime of the snippet in seconds; zero keeps the default. With
+// MaxViews set the snippet is deleted after being opened that many times.
+// ReadOnly shares the code without an edit token, reusing an identical
+// read-only snippet if there is one.
+type ShareRequest struct {
+	Code      string `json:"code"`
+	Parent    string `json:"parent,omitempty"`
+	Encrypted bool   `json:"encrypted,omitempty"`
+	ExpiresIn int64  `json:"expiresIn,omitempty"`
+	MaxViews  int    `json:"maxViews,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// SnippetRevision describes one revision in a snippet's history.
+type SnippetRevision struct {
+	ID        string    `json:"id"`
+	Parent    string    `json:"parent,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SnippetHistory lists the revisions leading to a snippet, oldest first, and
+// the unified diff from the revision named From to it.
+type SnippetHistory struct {
+	Lineage []SnippetRevision `json:"lineage"`
+	From    string            `json:"from,omitempty"`
+	Diff    string            `json:"diff,omitempty"`
+}
+
+// ShareResponse links to a shared snippet. EditToken is returned unless the
+// snippet was shared read-only; it is not stored and cannot be recovered.
+type ShareResponse struct {
+	ID        string     `json:"id"`
+	URL       string     `json:"url"`
+	EditToken string     `json:"editToken,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// SnippetStats describes the snippet storage. Totals are as of the last
+// reaper pass at ScannedAt; Reaped and Burned count deletions since the
+// server started.
+type SnippetStats struct {
+	Snippets  int        `json:"snippets"`
+	Bytes     int64      `json:"bytes"`
+	Encrypted int        `json:"encrypted"`
+	Expiring  int        `json:"expiring"`
+	Burnable  int        `json:"burnable"`
+	Corrupt   int        `json:"corrupt"`
+	Reaped    int64      `json:"reaped"`
+	Burned    int64      `json:"burned"`
+	ScannedAt *time.Time `json:"scannedAt,omitempty"`
+}
+
+// UpdateSnippetRequest replaces the code of a snippet in place.
+type UpdateSnippetRequest struct {
+	Code string `json:"code"`
+}
+
+// HomePage is the data rendered into form.html and embed.html. An empty
+// Code shows the default program, unless Ciphertext holds an encrypted
+// snippet for the browser to decrypt.
+type HomePage struct {
+	Code       string
+	Ciphertext string
+	SnippetID  string
+	// Example names the example shown, restored by the Reset button.
+	Example string
+	// Examples fills the examples menu.
+	Examples []ExampleCategory
+}
@@ -0,0 +1,52 @@
+package models
+
+// TraceResult is an execution trace together with a goroutine and processor
+// timeline extracted from it. All times are nanoseconds since the first event.
+type TraceResult struct {
+	Trace      []byte           `json:"trace,omitempty"`
+	Duration   int64            `json:"duration"`
+	Goroutines []TraceGoroutine `json:"goroutines"`
+	Procs      []TraceProc      `json:"procs"`
+	Ranges     []TraceRange     `json:"ranges,omitempty"`
+}
+
+// TraceGoroutine is the life of a single goroutine as a series of state segments.
+type TraceGoroutine struct {
+	ID        int64          `json:"id"`
+	Function  string         `json:"function,omitempty"`
+	System    bool           `json:"system"`
+	CreatedBy int64          `json:"createdBy,omitempty"`
+	CreatedAt int64          `json:"createdAt"`
+	EndedAt   int64          `json:"endedAt,omitempty"`
+	Segments  []TraceSegment `json:"segments"`
+}
+
+// TraceSegment is a span of time a goroutine spent in one state. Reason says
+// why a goroutine was blocked, e.g. "chan receive" or "sync".
+type TraceSegment struct {
+	Start  int64  `json:"start"`
+	End    int64  `json:"end"`
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// TraceProc lists which goroutines ran on a processor (P) and when.
+type TraceProc struct {
+	ID     int64        `json:"id"`
+	Slices []TraceSlice `json:"slices"`
+}
+
+type TraceSlice struct {
+	Start     int64 `json:"start"`
+	End       int64 `json:"end"`
+	Goroutine int64 `json:"goroutine"`
+}
+
+// TraceRange is a named runtime activity such as a GC phase or a
+// stop-the-world pause. Goroutine is set for ranges scoped to a goroutine.
+type TraceRange struct {
+	Name      string `json:"name"`
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	Goroutine int64  `json:"goroutine,omitempty"`
+}
@@ -0,0 +1,132 @@
+package profiling
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/google/pprof/profile"
+)
+
+// Summarize parses a pprof profile and computes its top table and flame graph
+// for the given sample type. An empty sampleType selects the profile's default.
+func Summarize(data []byte, sampleType string, topN int) (*models.ProfileData, error) {
+	p, err := profile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %v", err)
+	}
+	if len(p.SampleType) == 0 {
+		return nil, fmt.Errorf("profile has no sample types")
+	}
+
+	index := sampleIndex(p, sampleType)
+	result := &models.ProfileData{
+		Pprof:      data,
+		SampleType: p.SampleType[index].Type,
+		Unit:       p.SampleType[index].Unit,
+		FlameGraph: &models.FlameNode{Name: "root"},
+	}
+
+	flat := make(map[string]*models.ProfileEntry)
+	cum := make(map[string]int64)
+
+	for _, sample := range p.Sample {
+		value := sample.Value[index]
+		if value == 0 {
+			continue
+		}
+		result.Total += value
+
+		frames := sampleFrames(sample)
+		seen := make(map[string]bool)
+		for i, frame := range frames {
+			entry, ok := flat[frame.Function]
+			if !ok {
+				entry = &models.ProfileEntry{Function: frame.Function, File: frame.File}
+				flat[frame.Function] = entry
+			}
+			if i == 0 {
+				entry.Flat += value
+			}
+			// Recursive functions only count once towards their cumulative value.
+			if !seen[frame.Function] {
+				seen[frame.Function] = true
+				cum[frame.Function] += value
+			}
+		}
+
+		addFlameStack(result.FlameGraph, frames, value)
+	}
+	result.FlameGraph.Value = result.Total
+
+	for name, entry := range flat {
+		entry.Cum = cum[name]
+		result.Top = append(result.Top, *entry)
+	}
+	sort.Slice(result.Top, func(i, j int) bool {
+		if result.Top[i].Flat != result.Top[j].Flat {
+			return result.Top[i].Flat > result.Top[j].Flat
+		}
+		if result.Top[i].Cum != result.Top[j].Cum {
+			return result.Top[i].Cum > result.Top[j].Cum
+		}
+		return result.Top[i].Function < result.Top[j].Function
+	})
+	if len(result.Top) > topN {
+		result.Top = result.Top[:topN]
+	}
+
+	return result, nil
+}
+
+func sampleIndex(p *profile.Profile, sampleType string) int {
+	if sampleType == "" {
+		sampleType = p.DefaultSampleType
+	}
+	for i, st := range p.SampleType {
+		if st.Type == sampleType {
+			return i
+		}
+	}
+	return len(p.SampleType) - 1
+}
+
+// sampleFrames flattens a sample's locations, including inlined functions,
+// into frames ordered from leaf to root.
+func sampleFrames(sample *profile.Sample) []models.StackFrame {
+	var frames []models.StackFrame
+	for _, loc := range sample.Location {
+		for _, line := range loc.Line {
+			if line.Function == nil {
+				continue
+			}
+			frames = append(frames, models.StackFrame{
+				Function: line.Function.Name,
+				File:     line.Function.Filename,
+				Line:     int(line.Line),
+			})
+		}
+	}
+	return frames
+}
+
+// addFlameStack adds a leaf-to-root stack to the flame graph rooted at root.
+func addFlameStack(root *models.FlameNode, frames []models.StackFrame, value int64) {
+	node := root
+	for i := len(frames) - 1; i >= 0; i-- {
+		var child *models.FlameNode
+		for _, c := range node.Children {
+			if c.Name == frames[i].Function {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			child = &models.FlameNode{Name: frames[i].Function}
+			node.Children = append(node.Children, child)
+		}
+		child.Value += value
+		node = child
+	}
+}
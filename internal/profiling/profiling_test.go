@@ -0,0 +1,144 @@
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// cpu.pprof and heap.pprof were written with github.com/google/pprof/profile.
+// In cpu.pprof main.main calls the recursive main.fib (30ms) and main.work
+// (30ms, 10ms of it in main.add, inlined into it); heap.pprof has main.alloc
+// allocating 1024 bytes, 512 of them in use, and main.main 256 bytes.
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		sampleType string
+		topN       int
+		wantType   string
+		total      int64
+		top        []models.ProfileEntry
+	}{
+		{
+			name:     "cpu",
+			file:     "cpu.pprof",
+			topN:     10,
+			wantType: "cpu",
+			total:    60,
+			top: []models.ProfileEntry{
+				{Function: "main.fib", Flat: 30, Cum: 30},
+				{Function: "main.work", Flat: 20, Cum: 30},
+				{Function: "main.add", Flat: 10, Cum: 10},
+				{Function: "main.main", Flat: 0, Cum: 60},
+			},
+		},
+		{
+			name:       "cpu samples",
+			file:       "cpu.pprof",
+			sampleType: "samples",
+			topN:       2,
+			wantType:   "samples",
+			total:      6,
+			top: []models.ProfileEntry{
+				{Function: "main.fib", Flat: 3, Cum: 3},
+				{Function: "main.work", Flat: 2, Cum: 3},
+			},
+		},
+		{
+			name:     "heap default",
+			file:     "heap.pprof",
+			topN:     10,
+			wantType: "inuse_space",
+			total:    512,
+			top: []models.ProfileEntry{
+				{Function: "main.alloc", Flat: 512, Cum: 512},
+				{Function: "main.main", Flat: 0, Cum: 512},
+			},
+		},
+		{
+			name:       "heap allocations",
+			file:       "heap.pprof",
+			sampleType: "alloc_space",
+			topN:       10,
+			wantType:   "alloc_space",
+			total:      1280,
+			top: []models.ProfileEntry{
+				{Function: "main.alloc", Flat: 1024, Cum: 1024},
+				{Function: "main.main", Flat: 256, Cum: 1280},
+			},
+		},
+		{
+			name:       "unknown sample type",
+			file:       "heap.pprof",
+			sampleType: "nope",
+			topN:       1,
+			wantType:   "inuse_space",
+			total:      512,
+			top:        []models.ProfileEntry{{Function: "main.alloc", Flat: 512, Cum: 512}},
+		},
+	}
+	for _, test := range tests {
+		data, err := os.ReadFile("testdata/" + test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Summarize(data, test.sampleType, test.topN)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if result.SampleType != test.wantType || result.Total != test.total {
+			t.Errorf("%s: %s total %d, want %s total %d", test.name, result.SampleType, result.Total, test.wantType, test.total)
+		}
+		for i := range result.Top {
+			result.Top[i].File = ""
+		}
+		if !reflect.DeepEqual(result.Top, test.top) {
+			t.Errorf("%s: top =\n%+v\nwant\n%+v", test.name, result.Top, test.top)
+		}
+	}
+}
+
+func TestSummarizeFlameGraph(t *testing.T) {
+	data, err := os.ReadFile("testdata/cpu.pprof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Summarize(data, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &models.FlameNode{Name: "root", Value: 60, Children: []*models.FlameNode{
+		{Name: "main.main", Value: 60, Children: []*models.FlameNode{
+			{Name: "main.fib", Value: 30, Children: []*models.FlameNode{
+				{Name: "main.fib", Value: 30},
+			}},
+			{Name: "main.work", Value: 30, Children: []*models.FlameNode{
+				{Name: "main.add", Value: 10},
+			}},
+		}},
+	}}
+	if !reflect.DeepEqual(result.FlameGraph, want) {
+		t.Errorf("flame graph = %s, want %s", flameString(result.FlameGraph), flameString(want))
+	}
+}
+
+func TestSummarizeInvalid(t *testing.T) {
+	if _, err := Summarize([]byte("not a profile"), "", 10); err == nil {
+		t.Error("Summarize of invalid data succeeded")
+	}
+}
+
+func flameString(node *models.FlameNode) string {
+	s := fmt.Sprintf("%s(%d", node.Name, node.Value)
+	for _, child := range node.Children {
+		s += " " + flameString(child)
+	}
+	return s + ")"
+}
@@ -0,0 +1,207 @@
+package profiling
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+	"golang.org/x/exp/trace"
+)
+
+type goroutineState struct {
+	g      *models.TraceGoroutine
+	state  trace.GoState
+	since  int64
+	reason string
+}
+
+type runningSlice struct {
+	proc  trace.ProcID
+	start int64
+}
+
+type rangeKey struct {
+	name      string
+	goroutine trace.GoID
+}
+
+// Timeline parses a runtime/trace execution trace and extracts the
+// goroutine, processor and GC timelines from it.
+func Timeline(data []byte) (*models.TraceResult, error) {
+	r, err := trace.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace: %v", err)
+	}
+
+	result := &models.TraceResult{Trace: data}
+	goroutines := make(map[trace.GoID]*goroutineState)
+	procs := make(map[trace.ProcID]*models.TraceProc)
+	running := make(map[trace.GoID]runningSlice)
+	ranges := make(map[rangeKey]int64)
+
+	var start trace.Time
+	first := true
+	var now int64
+
+	for {
+		ev, err := r.ReadEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trace event: %v", err)
+		}
+
+		if first {
+			start = ev.Time()
+			first = false
+		}
+		now = int64(ev.Time() - start)
+
+		switch ev.Kind() {
+		case trace.EventStateTransition:
+			st := ev.StateTransition()
+			if st.Resource.Kind != trace.ResourceGoroutine {
+				continue
+			}
+			id := st.Resource.Goroutine()
+			from, to := st.Goroutine()
+
+			gs, ok := goroutines[id]
+			if !ok {
+				gs = &goroutineState{
+					g:     &models.TraceGoroutine{ID: int64(id), CreatedAt: now},
+					state: trace.GoUndetermined,
+					since: now,
+				}
+				goroutines[id] = gs
+			}
+
+			if from == trace.GoNotExist && to == trace.GoRunnable {
+				// On creation the transition stack is the new goroutine's start function.
+				gs.g.CreatedAt = now
+				gs.g.Function = rootFunction(st.Stack)
+				if creator := ev.Goroutine(); creator != trace.NoGoroutine {
+					gs.g.CreatedBy = int64(creator)
+				}
+			} else if gs.g.Function == "" {
+				gs.g.Function = rootFunction(st.Stack)
+			}
+
+			closeSegment(gs, now)
+			gs.state, gs.since, gs.reason = to, now, st.Reason
+			if to == trace.GoNotExist {
+				gs.g.EndedAt = now
+			}
+
+			if from == trace.GoRunning {
+				if slice, ok := running[id]; ok {
+					addSlice(procs, slice.proc, models.TraceSlice{Start: slice.start, End: now, Goroutine: int64(id)})
+					delete(running, id)
+				}
+			}
+			if to == trace.GoRunning && ev.Proc() != trace.NoProc {
+				running[id] = runningSlice{proc: ev.Proc(), start: now}
+			}
+
+		case trace.EventRangeBegin, trace.EventRangeActive:
+			rng := ev.Range()
+			ranges[rangeKey{rng.Name, rangeGoroutine(rng)}] = now
+
+		case trace.EventRangeEnd:
+			rng := ev.Range()
+			key := rangeKey{rng.Name, rangeGoroutine(rng)}
+			begin := ranges[key]
+			delete(ranges, key)
+			result.Ranges = append(result.Ranges, models.TraceRange{
+				Name:      rng.Name,
+				Start:     begin,
+				End:       now,
+				Goroutine: int64(key.goroutine),
+			})
+		}
+	}
+
+	result.Duration = now
+
+	// Close everything still open when the trace ended.
+	for id, slice := range running {
+		addSlice(procs, slice.proc, models.TraceSlice{Start: slice.start, End: now, Goroutine: int64(id)})
+	}
+	for key, begin := range ranges {
+		result.Ranges = append(result.Ranges, models.TraceRange{Name: key.name, Start: begin, End: now, Goroutine: int64(key.goroutine)})
+	}
+	for _, gs := range goroutines {
+		closeSegment(gs, now)
+		gs.g.System = strings.HasPrefix(gs.g.Function, "runtime.") && gs.g.Function != "runtime.main"
+		result.Goroutines = append(result.Goroutines, *gs.g)
+	}
+	for _, p := range procs {
+		result.Procs = append(result.Procs, *p)
+	}
+
+	sort.Slice(result.Goroutines, func(i, j int) bool { return result.Goroutines[i].ID < result.Goroutines[j].ID })
+	sort.Slice(result.Procs, func(i, j int) bool { return result.Procs[i].ID < result.Procs[j].ID })
+	sort.Slice(result.Ranges, func(i, j int) bool { return result.Ranges[i].Start < result.Ranges[j].Start })
+
+	return result, nil
+}
+
+// closeSegment ends the goroutine's current state segment at now.
+func closeSegment(gs *goroutineState, now int64) {
+	name := goStateName(gs.state)
+	if name == "" || now <= gs.since {
+		return
+	}
+	segment := models.TraceSegment{Start: gs.since, End: now, State: name}
+	if gs.state == trace.GoWaiting {
+		segment.Reason = gs.reason
+	}
+	gs.g.Segments = append(gs.g.Segments, segment)
+}
+
+func goStateName(state trace.GoState) string {
+	switch state {
+	case trace.GoRunnable:
+		return "runnable"
+	case trace.GoRunning:
+		return "running"
+	case trace.GoWaiting:
+		return "waiting"
+	case trace.GoSyscall:
+		return "syscall"
+	}
+	return ""
+}
+
+func addSlice(procs map[trace.ProcID]*models.TraceProc, id trace.ProcID, slice models.TraceSlice) {
+	p, ok := procs[id]
+	if !ok {
+		p = &models.TraceProc{ID: int64(id)}
+		procs[id] = p
+	}
+	p.Slices = append(p.Slices, slice)
+}
+
+func rangeGoroutine(rng trace.Range) trace.GoID {
+	if rng.Scope.Kind == trace.ResourceGoroutine {
+		return rng.Scope.Goroutine()
+	}
+	return 0
+}
+
+// rootFunction returns the outermost function of a stack, which is the
+// function the goroutine was started with.
+func rootFunction(stack trace.Stack) string {
+	var name string
+	stack.Frames(func(f trace.StackFrame) bool {
+		if f.Func != "runtime.goexit" {
+			name = f.Func
+		}
+		return true
+	})
+	return name
+}
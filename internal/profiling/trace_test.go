@@ -0,0 +1,105 @@
+package profiling
+
+import (
+	"os"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// trace.out was recorded with runtime/trace by a Go 1.22 program whose
+// main.main starts two main.worker goroutines summing numbers and waits for
+// them.
+
+func TestTimeline(t *testing.T) {
+	data, err := os.ReadFile("testdata/trace.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Timeline(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Duration <= 0 || len(result.Procs) == 0 {
+		t.Fatalf("duration %d with %d procs", result.Duration, len(result.Procs))
+	}
+
+	byFunction := make(map[string][]models.TraceGoroutine)
+	ids := make(map[int64]bool)
+	for _, g := range result.Goroutines {
+		byFunction[g.Function] = append(byFunction[g.Function], g)
+		ids[g.ID] = true
+		checkSegments(t, g, result.Duration)
+	}
+
+	mains := byFunction["main.main"]
+	if len(mains) != 1 || mains[0].System {
+		t.Fatalf("main.main goroutines = %+v, want one user goroutine", mains)
+	}
+	workers := byFunction["main.worker"]
+	if len(workers) != 2 {
+		t.Fatalf("got %d main.worker goroutines, want 2", len(workers))
+	}
+	for _, g := range workers {
+		if g.System || g.CreatedBy != mains[0].ID || g.EndedAt <= g.CreatedAt {
+			t.Errorf("worker %+v: want a user goroutine created by main.main that ended", g)
+		}
+	}
+	if runtime := byFunction["runtime.traceStartReadCPU.func1"]; len(runtime) != 1 || !runtime[0].System {
+		t.Errorf("runtime goroutines = %+v, want one system goroutine", runtime)
+	}
+
+	for _, p := range result.Procs {
+		for _, slice := range p.Slices {
+			if slice.Start >= slice.End || !ids[slice.Goroutine] {
+				t.Errorf("proc %d: invalid slice %+v", p.ID, slice)
+			}
+		}
+	}
+
+	var stw bool
+	for _, r := range result.Ranges {
+		stw = stw || r.Name == "stop-the-world (start trace)"
+	}
+	if !stw {
+		t.Errorf("ranges = %+v, want the stop-the-world starting the trace", result.Ranges)
+	}
+}
+
+// checkSegments checks that the segments of g are ordered, do not overlap
+// and stay within the trace.
+func checkSegments(t *testing.T, g models.TraceGoroutine, duration int64) {
+	t.Helper()
+	var end int64
+	for _, s := range g.Segments {
+		if s.Start < end || s.End <= s.Start || s.End > duration {
+			t.Errorf("goroutine %d: segment %+v after %d", g.ID, s, end)
+		}
+		switch s.State {
+		case "runnable", "running", "syscall":
+			if s.Reason != "" {
+				t.Errorf("goroutine %d: %s segment with reason %q", g.ID, s.State, s.Reason)
+			}
+		case "waiting":
+		default:
+			t.Errorf("goroutine %d: unknown state %q", g.ID, s.State)
+		}
+		end = s.End
+	}
+}
+
+func TestTimelineInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"not a trace", "not a trace"},
+		{"truncated", "go 1.22 trace\x00\x00\x00"},
+	}
+	for _, test := range tests {
+		if _, err := Timeline([]byte(test.data)); err == nil {
+			t.Errorf("%s: Timeline succeeded", test.name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/docker"
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/AlexandruC0909/playground/internal/utils"
+)
+
+// Run validates, compiles and runs code the way /run does, feeding it input
+// line by line, and collects its output once it exits. Output beyond
+// config.MaxOutputSize is dropped. Each run gets a workspace of its own, so
+// runs may be concurrent.
+func Run(ctx context.Context, executor *docker.Executor, code, input string, opts models.RunOptions) models.RunResult {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, config.TimeoutSeconds*time.Second)
+	defer cancel()
+
+	session := models.NewSession()
+	session.Code = code
+
+	errc := make(chan error, 1)
+	go func() {
+		defer session.Close()
+		errc <- execute(ctx, executor, session, input, opts)
+	}()
+
+	var result models.RunResult
+	var output, stderr strings.Builder
+	outputs := session.OutputChan
+	for outputs != nil {
+		select {
+		case out, ok := <-outputs:
+			switch {
+			case !ok:
+				outputs = nil
+			case out.Done:
+				result.Error = out.Error
+			default:
+				if output.Len()+len(out.Output) <= config.MaxOutputSize {
+					output.WriteString(out.Output)
+				}
+				if stderr.Len()+len(out.Error) <= config.MaxOutputSize {
+					stderr.WriteString(out.Error)
+				}
+			}
+		case <-session.Done:
+			outputs = nil
+		}
+	}
+
+	if err := <-errc; err != nil {
+		result.Error = err.Error()
+	}
+	result.Output = output.String()
+	result.Stderr = stderr.String()
+	result.Duration = time.Since(start)
+	return result
+}
+
+// execute runs the program of session, feeding it input while it runs.
+func execute(ctx context.Context, executor *docker.Executor, session *models.ProgramSession, input string, opts models.RunOptions) error {
+	if err := utils.ValidateAndPrepare(session.Code, session); err != nil {
+		return err
+	}
+	dir, remove, err := executor.Workspace(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer remove()
+	opts.Dir = dir
+
+	if err := executor.Compile(ctx, session.Code, opts); err != nil {
+		return err
+	}
+
+	// The feeder must be gone before the session is closed, which closes
+	// its input channel.
+	stop := make(chan struct{})
+	fed := make(chan struct{})
+	go func() {
+		defer close(fed)
+		for _, line := range inputLines(input) {
+			select {
+			case session.InputChan <- line:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	err = executor.Run(ctx, session, opts)
+	close(stop)
+	<-fed
+	return err
+}
+
+func inputLines(input string) []string {
+	if input == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(input, "\n"), "\n")
+}
+
+// MatchOutput reports whether got is the expected output want, ignoring
+// carriage returns, trailing spaces on each line and trailing blank lines.
+func MatchOutput(got, want string) bool {
+	return normalize(got) == normalize(want)
+}
+
+func normalize(output string) string {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,236 @@
+package similarity
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// Submission is a program to compare with the others.
+type Submission struct {
+	Name string
+	Code string
+}
+
+// Analyze compares every pair of submissions by structure and reports the
+// pairs at least threshold similar, grouped into clusters of submissions
+// linked by such pairs.
+//
+// Programs are reduced to the shape of their syntax tree: identifiers,
+// literal values, comments and formatting are dropped, so renaming variables
+// or reformatting does not hide copying. The similarity of two programs is
+// the share of the smaller one's fingerprints found in the other. Code from
+// base, the starter code the submissions share, is not counted, and programs
+// with fewer than config.MinSimilarityFingerprints fingerprints left are
+// skipped as too short to tell.
+func Analyze(submissions []Submission, base string, threshold float64) models.SimilarityReport {
+	report := models.SimilarityReport{
+		Threshold: threshold,
+		Pairs:     []models.SimilarityPair{},
+		Clusters:  []models.SimilarityCluster{},
+	}
+
+	// Starter code that does not parse, like a partial program, is simply
+	// not subtracted.
+	baseFingerprints, _ := Fingerprint(base)
+
+	type fingerprinted struct {
+		name         string
+		fingerprints map[uint64]bool
+	}
+	var programs []fingerprinted
+	for _, submission := range submissions {
+		fingerprints, err := Fingerprint(submission.Code)
+		if err != nil {
+			report.Skipped = append(report.Skipped, models.SimilaritySkip{Name: submission.Name, Reason: "does not parse"})
+			continue
+		}
+		for fingerprint := range baseFingerprints {
+			delete(fingerprints, fingerprint)
+		}
+		if len(fingerprints) < config.MinSimilarityFingerprints {
+			report.Skipped = append(report.Skipped, models.SimilaritySkip{Name: submission.Name, Reason: "too short"})
+			continue
+		}
+		programs = append(programs, fingerprinted{submission.Name, fingerprints})
+	}
+	report.Compared = len(programs)
+
+	clusters := newUnionFind(len(programs))
+	for i := range programs {
+		for j := i + 1; j < len(programs); j++ {
+			score := overlap(programs[i].fingerprints, programs[j].fingerprints)
+			if score < threshold {
+				continue
+			}
+			report.Pairs = append(report.Pairs, models.SimilarityPair{A: programs[i].name, B: programs[j].name, Score: score})
+			clusters.union(i, j, score)
+		}
+	}
+	sort.SliceStable(report.Pairs, func(i, j int) bool {
+		return report.Pairs[i].Score > report.Pairs[j].Score
+	})
+
+	members := make(map[int][]string)
+	for i, program := range programs {
+		root := clusters.find(i)
+		members[root] = append(members[root], program.name)
+	}
+	for root, names := range members {
+		if len(names) > 1 {
+			report.Clusters = append(report.Clusters, models.SimilarityCluster{Members: names, Score: clusters.score[root]})
+		}
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		a, b := report.Clusters[i], report.Clusters[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return len(a.Members) > len(b.Members)
+	})
+	return report
+}
+
+// Fingerprint returns the winnowed hashes of the k-grams of code's
+// normalised syntax tree.
+func Fingerprint(code string) (map[uint64]bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", code, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse code: %v", err)
+	}
+
+	tokens := normalize(file)
+	var hashes []uint64
+	for i := 0; i+config.SimilarityKGram <= len(tokens); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(tokens[i:i+config.SimilarityKGram], " ")))
+		hashes = append(hashes, h.Sum64())
+	}
+	return winnow(hashes, config.SimilarityWindow), nil
+}
+
+// normalize flattens file into the kinds of its nodes, in source order, with
+// the operators of expressions and statements and the kinds of literals but
+// no names, values or comments. Closing markers keep the nesting. Imports,
+// which say little about how a program was written, are left out.
+func normalize(file *ast.File) []string {
+	var tokens []string
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			continue
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if _, ok := n.(*ast.CommentGroup); ok {
+				return false
+			}
+			tokens = append(tokens, nodeToken(n))
+			return true
+		})
+	}
+	return tokens
+}
+
+func nodeToken(n ast.Node) string {
+	switch x := n.(type) {
+	case nil:
+		return ")"
+	case *ast.BasicLit:
+		return x.Kind.String()
+	case *ast.BinaryExpr:
+		return x.Op.String()
+	case *ast.UnaryExpr:
+		return "unary" + x.Op.String()
+	case *ast.AssignStmt:
+		return x.Tok.String()
+	case *ast.IncDecStmt:
+		return x.Tok.String()
+	case *ast.BranchStmt:
+		return x.Tok.String()
+	case *ast.GenDecl:
+		return x.Tok.String()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+}
+
+// winnow selects the smallest hash of every window of w consecutive hashes,
+// which keeps matches of at least w+k-1 tokens detectable with far fewer
+// fingerprints. Programs shorter than a window keep all their hashes.
+func winnow(hashes []uint64, w int) map[uint64]bool {
+	fingerprints := make(map[uint64]bool)
+	if len(hashes) < w {
+		for _, h := range hashes {
+			fingerprints[h] = true
+		}
+		return fingerprints
+	}
+	for i := 0; i+w <= len(hashes); i++ {
+		min := hashes[i]
+		for _, h := range hashes[i+1 : i+w] {
+			if h < min {
+				min = h
+			}
+		}
+		fingerprints[min] = true
+	}
+	return fingerprints
+}
+
+// overlap returns the share of the smaller set found in the larger.
+func overlap(a, b map[uint64]bool) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(a) == 0 {
+		return 0
+	}
+	shared := 0
+	for fingerprint := range a {
+		if b[fingerprint] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a))
+}
+
+// unionFind groups programs into clusters, tracking the highest pair score
+// of each.
+type unionFind struct {
+	parent []int
+	score  map[int]float64
+}
+
+func newUnionFind(n int) *unionFind {
+	u := &unionFind{parent: make([]int, n), score: make(map[int]float64)}
+	for i := range u.parent {
+		u.parent[i] = i
+	}
+	return u
+}
+
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+func (u *unionFind) union(i, j int, score float64) {
+	a, b := u.find(i), u.find(j)
+	best := score
+	if u.score[a] > best {
+		best = u.score[a]
+	}
+	if u.score[b] > best {
+		best = u.score[b]
+	}
+	u.parent[b] = a
+	u.score[a] = best
+}
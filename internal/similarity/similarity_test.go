@@ -0,0 +1,233 @@
+package similarity
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+const original = `package main
+
+import "fmt"
+
+func sort(values []int) {
+	for i := 0; i < len(values); i++ {
+		for j := 0; j < len(values)-i-1; j++ {
+			if values[j] > values[j+1] {
+				values[j], values[j+1] = values[j+1], values[j]
+			}
+		}
+	}
+}
+
+func main() {
+	values := []int{5, 2, 9, 1}
+	sort(values)
+	for i, v := range values {
+		fmt.Printf("%d: %d\n", i, v)
+	}
+}
+`
+
+// renamed is original with other names, values, comments and formatting.
+const renamed = `package main
+
+import "fmt"
+
+// order sorts the numbers in place.
+func order(xs []int) {
+	for a := 0; a < len(xs); a++ {
+		for b := 0; b < len(xs)-a-1; b++ {
+			if xs[b] > xs[b+1] { xs[b], xs[b+1] = xs[b+1], xs[b] }
+		}
+	}
+}
+
+func main() {
+	numbers := []int{7, 3, 8, 4}
+	order(numbers)
+	for idx, n := range numbers { fmt.Printf("%d=%d\n", idx, n) }
+}
+`
+
+// different solves another problem.
+const different = `package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	scanner := bufio.NewScanner(os.Stdin)
+	counts := make(map[string]int)
+	for scanner.Scan() {
+		for _, word := range strings.Fields(scanner.Text()) {
+			counts[strings.ToLower(word)]++
+		}
+	}
+	best, most := "", 0
+	for word, count := range counts {
+		if count > most {
+			best, most = word, count
+		}
+	}
+	fmt.Println(best, most)
+}
+`
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name        string
+		submissions []Submission
+		base        string
+		pairs       []models.SimilarityPair
+		clusters    []models.SimilarityCluster
+		skipped     []models.SimilaritySkip
+	}{
+		{
+			name:        "renamed clone",
+			submissions: []Submission{{"ann", original}, {"bob", renamed}, {"cy", different}},
+			pairs:       []models.SimilarityPair{{A: "ann", B: "bob", Score: 1}},
+			clusters:    []models.SimilarityCluster{{Members: []string{"ann", "bob"}, Score: 1}},
+		},
+		{
+			name:        "transitive cluster",
+			submissions: []Submission{{"ann", original}, {"bob", renamed}, {"cy", original}},
+			pairs: []models.SimilarityPair{
+				{A: "ann", B: "bob", Score: 1},
+				{A: "ann", B: "cy", Score: 1},
+				{A: "bob", B: "cy", Score: 1},
+			},
+			clusters: []models.SimilarityCluster{{Members: []string{"ann", "bob", "cy"}, Score: 1}},
+		},
+		{
+			// Unchanged starter code is left with nothing to compare.
+			name:        "starter code",
+			submissions: []Submission{{"ann", original}, {"bob", renamed}, {"cy", different}},
+			base:        original,
+			skipped: []models.SimilaritySkip{
+				{Name: "ann", Reason: "too short"},
+				{Name: "bob", Reason: "too short"},
+			},
+		},
+		{
+			name:        "unparsable and short",
+			submissions: []Submission{{"ann", "package main\nfunc {"}, {"bob", "package main\n\nfunc main() {}\n"}},
+			skipped: []models.SimilaritySkip{
+				{Name: "ann", Reason: "does not parse"},
+				{Name: "bob", Reason: "too short"},
+			},
+		},
+	}
+	for _, test := range tests {
+		report := Analyze(test.submissions, test.base, 0.8)
+		if !reflect.DeepEqual(report.Pairs, append([]models.SimilarityPair{}, test.pairs...)) {
+			t.Errorf("%s: pairs = %+v, want %+v", test.name, report.Pairs, test.pairs)
+		}
+		if !reflect.DeepEqual(report.Clusters, append([]models.SimilarityCluster{}, test.clusters...)) {
+			t.Errorf("%s: clusters = %+v, want %+v", test.name, report.Clusters, test.clusters)
+		}
+		if !reflect.DeepEqual(report.Skipped, test.skipped) {
+			t.Errorf("%s: skipped = %+v, want %+v", test.name, report.Skipped, test.skipped)
+		}
+		if want := len(test.submissions) - len(test.skipped); report.Compared != want {
+			t.Errorf("%s: compared %d, want %d", test.name, report.Compared, want)
+		}
+	}
+}
+
+// A clone with a small structural change still scores above the threshold.
+func TestAnalyzeEditedClone(t *testing.T) {
+	edited := strings.Replace(renamed, `fmt.Printf("%d=%d\n", idx, n)`, `fmt.Fprintf(os.Stdout, "%d=%d\n", idx, n)`, 1)
+	report := Analyze([]Submission{{"ann", original}, {"bob", edited}}, "", 0.8)
+	if len(report.Pairs) != 1 || report.Pairs[0].Score == 1 {
+		t.Errorf("pairs = %+v, want one pair scoring under 1", report.Pairs)
+	}
+}
+
+func TestAnalyzeDifferentPrograms(t *testing.T) {
+	report := Analyze([]Submission{{"ann", original}, {"cy", different}}, "", 0.01)
+	if len(report.Pairs) > 0 && report.Pairs[0].Score >= 0.8 {
+		t.Errorf("unrelated programs scored %v", report.Pairs[0].Score)
+	}
+}
+
+// Code added to the starter code is still compared.
+func TestAnalyzeAddedToBase(t *testing.T) {
+	base := "package main\n\nfunc main() {\n}\n"
+	extended := strings.Replace(original, "func main() {", "func main() {\n\tdefer func() {}()", 1)
+	report := Analyze([]Submission{{"ann", original}, {"bob", extended}}, base, 0.8)
+	if len(report.Clusters) != 1 {
+		t.Errorf("clusters = %+v, want ann and bob together", report.Clusters)
+	}
+}
+
+func TestFingerprintIgnoresNamesAndFormatting(t *testing.T) {
+	a, err := Fingerprint(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Fingerprint(renamed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("renamed copy has different fingerprints: %d vs %d", len(a), len(b))
+	}
+	if _, err := Fingerprint("not go"); err == nil {
+		t.Error("Fingerprint of invalid code succeeded")
+	}
+}
+
+func TestWinnow(t *testing.T) {
+	tests := []struct {
+		name   string
+		hashes []uint64
+		w      int
+		want   []uint64
+	}{
+		{"empty", nil, 4, nil},
+		{"shorter than window", []uint64{3, 1}, 4, []uint64{1, 3}},
+		{"minimum per window", []uint64{5, 3, 8, 9, 7, 2}, 3, []uint64{2, 3, 7}},
+	}
+	for _, test := range tests {
+		got := winnow(test.hashes, test.w)
+		want := make(map[uint64]bool)
+		for _, h := range test.want {
+			want[h] = true
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: winnow = %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	set := func(hashes ...uint64) map[uint64]bool {
+		s := make(map[uint64]bool)
+		for _, h := range hashes {
+			s[h] = true
+		}
+		return s
+	}
+	tests := []struct {
+		name string
+		a, b map[uint64]bool
+		want float64
+	}{
+		{"empty", set(), set(1), 0},
+		{"subset", set(1, 2), set(1, 2, 3, 4), 1},
+		{"half", set(1, 2, 3, 4), set(3, 4, 5, 6, 7), 0.5},
+		{"disjoint", set(1), set(2), 0},
+	}
+	for _, test := range tests {
+		if got := overlap(test.a, test.b); got != test.want {
+			t.Errorf("%s: overlap = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
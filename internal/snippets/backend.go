@@ -0,0 +1,52 @@
+package snippets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// Backend persists snippets. Implementations store records as given; hashing,
+// validation and integrity checks are done by Store.
+type Backend interface {
+	// Get returns ErrNotFound for unknown IDs.
+	Get(id string) (models.Snippet, error)
+	// Put creates or replaces the snippet with the same ID.
+	Put(snippet models.Snippet) error
+	Delete(id string) error
+	// Walk calls fn for every stored snippet until fn returns an error.
+	// Records that cannot be decoded are passed to fn with an error wrapping
+	// ErrCorrupt instead of ending the walk; Walk itself only fails when the
+	// backend cannot be read.
+	Walk(fn func(models.Snippet, error) error) error
+	Close() error
+}
+
+const (
+	BackendFilesystem = "filesystem"
+	BackendBolt       = "bolt"
+	BackendMemory     = "memory"
+)
+
+// Open opens the backend of the given kind at location, a directory for the
+// filesystem backend and a database file for bolt. The memory backend
+// ignores location.
+func Open(kind string, location string) (Backend, error) {
+	switch kind {
+	case BackendFilesystem:
+		return NewFilesystemBackend(location)
+	case BackendBolt:
+		return NewBoltBackend(location)
+	case BackendMemory:
+		return NewMemoryBackend(), nil
+	}
+	return nil, fmt.Errorf("unknown snippet backend %q", kind)
+}
+
+// OpenSpec opens a backend described as "kind:location", e.g.
+// "bolt:data/snippets.db".
+func OpenSpec(spec string) (Backend, error) {
+	kind, location, _ := strings.Cut(spec, ":")
+	return Open(kind, location)
+}
@@ -0,0 +1,146 @@
+package snippets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBackends(t *testing.T) {
+	for _, test := range backendTests(t) {
+		t.Run(test.name, func(t *testing.T) {
+			b := test.backend
+			defer b.Close()
+
+			snippet := models.Snippet{ID: ID("package main", ""), Code: "package main", Checksum: Checksum("package main")}
+			if _, err := b.Get(snippet.ID); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get before Put: got %v, want ErrNotFound", err)
+			}
+			if err := b.Put(snippet); err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.Get(snippet.ID)
+			if err != nil || got != snippet {
+				t.Fatalf("Get = %+v, %v; want %+v", got, err, snippet)
+			}
+			if err := b.Delete(snippet.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := b.Get(snippet.ID); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get after Delete: got %v, want ErrNotFound", err)
+			}
+			if err := b.Delete(snippet.ID); err != nil {
+				t.Fatalf("Delete of a missing snippet: %v", err)
+			}
+		})
+	}
+}
+
+func TestWalkSkipsCorruptRecords(t *testing.T) {
+	for _, test := range backendTests(t) {
+		if test.corrupt == nil {
+			continue
+		}
+		t.Run(test.name, func(t *testing.T) {
+			b := test.backend
+			defer b.Close()
+
+			for _, code := range []string{"package a", "package b"} {
+				if err := b.Put(models.Snippet{ID: ID(code, ""), Code: code, Checksum: Checksum(code)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			test.corrupt(t, "badbadbadbad", []byte(`{"id": "badbadbadbad", "code": `))
+
+			var ids []string
+			corrupt := 0
+			err := b.Walk(func(snippet models.Snippet, err error) error {
+				if err != nil {
+					if !errors.Is(err, ErrCorrupt) {
+						t.Errorf("record error %v does not wrap ErrCorrupt", err)
+					}
+					corrupt++
+					return nil
+				}
+				ids = append(ids, snippet.ID)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk: %v", err)
+			}
+			sort.Strings(ids)
+			want := []string{ID("package a", ""), ID("package b", "")}
+			sort.Strings(want)
+			if len(ids) != 2 || ids[0] != want[0] || ids[1] != want[1] || corrupt != 1 {
+				t.Errorf("Walk saw %v and %d corrupt; want %v and 1 corrupt", ids, corrupt, want)
+			}
+		})
+	}
+}
+
+func TestWalkStopsOnCallbackError(t *testing.T) {
+	b := NewMemoryBackend()
+	for _, code := range []string{"package a", "package b"} {
+		b.Put(models.Snippet{ID: ID(code, ""), Code: code})
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := b.Walk(func(models.Snippet, error) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Walk = %v after %d calls; want %v after 1", err, calls, stop)
+	}
+}
+
+type backendTest struct {
+	name    string
+	backend Backend
+	// corrupt stores data as the raw record of id, bypassing encoding.
+	corrupt func(t *testing.T, id string, data []byte)
+}
+
+func backendTests(t *testing.T) []backendTest {
+	dir := t.TempDir()
+
+	fs, err := NewFilesystemBackend(filepath.Join(dir, "snippets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewBoltBackend(filepath.Join(dir, "snippets.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []backendTest{
+		{
+			name:    BackendFilesystem,
+			backend: fs,
+			corrupt: func(t *testing.T, id string, data []byte) {
+				if err := os.WriteFile(fs.path(id), data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name:    BackendBolt,
+			backend: db,
+			corrupt: func(t *testing.T, id string, data []byte) {
+				err := db.db.Update(func(tx *bolt.Tx) error {
+					return tx.Bucket(snippetBucket).Put([]byte(id), data)
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{name: BackendMemory, backend: NewMemoryBackend()},
+	}
+}
@@ -0,0 +1,96 @@
+package snippets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+var snippetBucket = []byte("snippets")
+
+// BoltBackend keeps snippets in a single-file embedded bbolt database.
+type BoltBackend struct {
+	db *bolt.DB
+}
+
+func NewBoltBackend(path string) (*BoltBackend, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snippet database directory: %v", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snippet database: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(snippetBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize snippet database: %v", err)
+	}
+
+	return &BoltBackend{db: db}, nil
+}
+
+func (b *BoltBackend) Get(id string) (models.Snippet, error) {
+	var data []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(snippetBucket).Get([]byte(id)); v != nil {
+			// Values are only valid for the life of the transaction.
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Snippet{}, fmt.Errorf("failed to read snippet: %v", err)
+	}
+	if data == nil {
+		return models.Snippet{}, ErrNotFound
+	}
+	return decodeSnippet(id, data)
+}
+
+func (b *BoltBackend) Put(snippet models.Snippet) error {
+	data, err := json.Marshal(snippet)
+	if err != nil {
+		return fmt.Errorf("failed to encode snippet: %v", err)
+	}
+
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snippetBucket).Put([]byte(snippet.ID), data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store snippet: %v", err)
+	}
+	return nil
+}
+
+func (b *BoltBackend) Delete(id string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snippetBucket).Delete([]byte(id))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete snippet: %v", err)
+	}
+	return nil
+}
+
+func (b *BoltBackend) Walk(fn func(models.Snippet, error) error) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(snippetBucket).ForEach(func(k, v []byte) error {
+			return fn(decodeSnippet(string(k), v))
+		})
+	})
+}
+
+func (b *BoltBackend) Close() error {
+	return b.db.Close()
+}
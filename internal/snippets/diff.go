@@ -0,0 +1,16 @@
+package snippets
+
+import (
+	"fmt"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// Diff returns the unified diff turning the code of from into that of to.
+func Diff(from, to models.Snippet) string {
+	edits := myers.ComputeEdits(span.URIFromPath(from.ID), from.Code, to.Code)
+	return fmt.Sprint(gotextdiff.ToUnified(from.ID+"/main.go", to.ID+"/main.go", from.Code, edits))
+}
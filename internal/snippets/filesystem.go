@@ -0,0 +1,111 @@
+package snippets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// FilesystemBackend keeps snippets as one JSON file per snippet in a
+// directory.
+type FilesystemBackend struct {
+	dir string
+}
+
+func NewFilesystemBackend(dir string) (*FilesystemBackend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snippet directory: %v", err)
+	}
+	return &FilesystemBackend{dir: dir}, nil
+}
+
+func (b *FilesystemBackend) Get(id string) (models.Snippet, error) {
+	data, err := os.ReadFile(b.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return models.Snippet{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Snippet{}, fmt.Errorf("failed to read snippet: %v", err)
+	}
+	return decodeSnippet(id, data)
+}
+
+func (b *FilesystemBackend) Put(snippet models.Snippet) error {
+	data, err := json.Marshal(snippet)
+	if err != nil {
+		return fmt.Errorf("failed to encode snippet: %v", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial snippet.
+	tmp, err := os.CreateTemp(b.dir, snippet.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to store snippet: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to store snippet: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store snippet: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), b.path(snippet.ID)); err != nil {
+		return fmt.Errorf("failed to store snippet: %v", err)
+	}
+	return nil
+}
+
+func (b *FilesystemBackend) Delete(id string) error {
+	if err := os.Remove(b.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete snippet: %v", err)
+	}
+	return nil
+}
+
+func (b *FilesystemBackend) Walk(fn func(models.Snippet, error) error) error {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list snippets: %v", err)
+	}
+
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		snippet, err := b.Get(id)
+		if errors.Is(err, ErrNotFound) {
+			// Deleted since the directory was listed.
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrCorrupt) {
+			return err
+		}
+		if err := fn(snippet, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *FilesystemBackend) Close() error {
+	return nil
+}
+
+func (b *FilesystemBackend) path(id string) string {
+	return filepath.Join(b.dir, id+".json")
+}
+
+func decodeSnippet(id string, data []byte) (models.Snippet, error) {
+	var snippet models.Snippet
+	if err := json.Unmarshal(data, &snippet); err != nil {
+		return models.Snippet{}, fmt.Errorf("%w: failed to decode snippet %s: %v", ErrCorrupt, id, err)
+	}
+	return snippet, nil
+}
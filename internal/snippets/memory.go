@@ -0,0 +1,65 @@
+package snippets
+
+import (
+	"sync"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// MemoryBackend keeps snippets in memory only. It is meant for tests and
+// throwaway instances.
+type MemoryBackend struct {
+	mu       sync.RWMutex
+	snippets map[string]models.Snippet
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{snippets: make(map[string]models.Snippet)}
+}
+
+func (b *MemoryBackend) Get(id string) (models.Snippet, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	snippet, ok := b.snippets[id]
+	if !ok {
+		return models.Snippet{}, ErrNotFound
+	}
+	return snippet, nil
+}
+
+func (b *MemoryBackend) Put(snippet models.Snippet) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.snippets[snippet.ID] = snippet
+	return nil
+}
+
+func (b *MemoryBackend) Delete(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.snippets, id)
+	return nil
+}
+
+func (b *MemoryBackend) Walk(fn func(models.Snippet, error) error) error {
+	b.mu.RLock()
+	snippets := make([]models.Snippet, 0, len(b.snippets))
+	for _, snippet := range b.snippets {
+		snippets = append(snippets, snippet)
+	}
+	b.mu.RUnlock()
+
+	for _, snippet := range snippets {
+		if err := fn(snippet, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,94 @@
+package snippets
+
+import (
+	"log"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// StartReaper deletes expired snippets every interval until the store is
+// closed, refreshing the storage statistics on each pass.
+func (s *Store) StartReaper(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.Reap(); err != nil {
+				log.Printf("Snippet reaper: %v\n", err)
+			}
+
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Reap deletes every expired snippet and returns how many were deleted.
+// Records that cannot be decoded are logged and left alone. If the backend
+// fails partway, the expired snippets found so far are still deleted but the
+// statistics are kept from the last full pass.
+func (s *Store) Reap() (int, error) {
+	now := time.Now()
+	stats := models.SnippetStats{ScannedAt: &now}
+	var expired []string
+
+	// Backends may hold a read transaction while walking, so deletions wait
+	// until the walk is over.
+	walkErr := s.backend.Walk(func(snippet models.Snippet, err error) error {
+		if err != nil {
+			log.Printf("Snippet reaper: %v\n", err)
+			stats.Corrupt++
+			return nil
+		}
+		if Expired(snippet, now) {
+			expired = append(expired, snippet.ID)
+			return nil
+		}
+
+		stats.Snippets++
+		stats.Bytes += int64(len(snippet.Code))
+		if snippet.Encrypted {
+			stats.Encrypted++
+		}
+		if snippet.ExpiresAt != nil {
+			stats.Expiring++
+		}
+		if snippet.MaxViews > 0 {
+			stats.Burnable++
+		}
+		return nil
+	})
+
+	deleted := 0
+	for _, id := range expired {
+		if err := s.backend.Delete(id); err != nil {
+			log.Printf("Failed to delete expired snippet %s: %v\n", id, err)
+			continue
+		}
+		deleted++
+	}
+
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	if walkErr != nil {
+		s.stats.Reaped += int64(deleted)
+		return deleted, walkErr
+	}
+	stats.Reaped = s.stats.Reaped + int64(deleted)
+	stats.Burned = s.stats.Burned
+	s.stats = stats
+
+	return deleted, nil
+}
+
+// Stats returns the storage statistics gathered by the last reaper pass.
+func (s *Store) Stats() models.SnippetStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats
+}
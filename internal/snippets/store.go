@@ -0,0 +1,403 @@
+package snippets
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+var (
+	// ErrNotFound is returned for IDs that name no stored snippet.
+	ErrNotFound = errors.New("snippet not found")
+	// ErrTooLarge is returned for code over config.MaxCodeSize.
+	ErrTooLarge = fmt.Errorf("snippet exceeds %d bytes", config.MaxCodeSize)
+	// ErrCorrupt is returned for stored snippets that fail their integrity
+	// check.
+	ErrCorrupt = errors.New("snippet is corrupt")
+	// ErrParentNotFound is returned when saving a revision of an unknown
+	// snippet.
+	ErrParentNotFound = errors.New("parent snippet not found")
+	// ErrForbidden is returned for edits without the snippet's edit token.
+	ErrForbidden = errors.New("invalid edit token")
+	// ErrInvalidCiphertext is returned for encrypted snippets that are not
+	// a base64 nonce and ciphertext.
+	ErrInvalidCiphertext = errors.New("encrypted snippet is not valid base64 AES-GCM ciphertext")
+)
+
+// gcmOverhead is the size of the nonce and authentication tag that AES-GCM
+// adds to encrypted code.
+const gcmOverhead = 12 + 16
+
+// Options control how a snippet is saved.
+type Options struct {
+	// Encrypted marks the code as client-side encrypted.
+	Encrypted bool
+	// TTL is how long the snippet is kept. Zero selects the default,
+	// config.DefaultSnippetTTLHours for plain snippets and
+	// config.PrivateSnippetTTLHours for encrypted ones. It is capped at
+	// config.MaxSnippetTTLHours.
+	TTL time.Duration
+	// MaxViews, if set, deletes the snippet once it has been viewed that
+	// many times.
+	MaxViews int
+	// ReadOnly saves the snippet without an edit token. Only read-only
+	// snippets are deduplicated: saving code that is already stored
+	// read-only, with the same view limit and expiry, returns the existing
+	// snippet.
+	ReadOnly bool
+}
+
+// dedupSlack is how far apart the expiries of a read-only snippet and of the
+// same code saved again may be for the existing snippet to be reused.
+const dedupSlack = time.Minute
+
+var idRe = regexp.MustCompile(`^[A-Za-z0-9_-]{12}$`)
+
+// Store shares snippets on top of a Backend.
+type Store struct {
+	backend Backend
+	// mu serializes writes so that edits do not race with each other.
+	mu sync.Mutex
+
+	statsMu sync.Mutex
+	stats   models.SnippetStats
+	done    chan struct{}
+}
+
+func NewStore(backend Backend) *Store {
+	return &Store{backend: backend, done: make(chan struct{})}
+}
+
+// ID returns the content-hash ID of code saved read-only as a revision of
+// parent, or as a new snippet if parent is empty. Saving the same code twice
+// yields the same ID.
+func ID(code string, parent string) string {
+	h := sha256.New()
+	if parent != "" {
+		h.Write([]byte(parent))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(code))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:9])
+}
+
+// Checksum returns the hex SHA-256 of data, used as the integrity checksum of
+// code and to store edit tokens.
+func Checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// Save stores code as a new revision of parent, or as a new snippet if parent
+// is empty, and returns it with the token allowing to edit it, unless
+// opts.ReadOnly is set. Editable snippets get a random ID so that nobody else
+// holds the token of a link returned to the sharer.
+func (s *Store) Save(code string, parent string, opts Options) (models.Snippet, string, error) {
+	if err := validateCode(code, opts.Encrypted); err != nil {
+		return models.Snippet{}, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if parent != "" {
+		if _, err := s.Get(parent); errors.Is(err, ErrNotFound) {
+			return models.Snippet{}, "", ErrParentNotFound
+		} else if err != nil {
+			return models.Snippet{}, "", err
+		}
+	}
+
+	now := time.Now().UTC()
+	snippet := models.Snippet{
+		Parent:    parent,
+		Code:      code,
+		Encrypted: opts.Encrypted,
+		Checksum:  Checksum(code),
+		CreatedAt: now,
+		MaxViews:  opts.MaxViews,
+	}
+	if ttl := opts.ttl(); ttl > 0 {
+		expiresAt := now.Add(ttl)
+		snippet.ExpiresAt = &expiresAt
+	}
+
+	var token string
+	if opts.ReadOnly {
+		snippet.ID = ID(code, parent)
+		existing, err := s.Get(snippet.ID)
+		if err == nil && sameContent(existing, snippet) {
+			return existing, "", nil
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return models.Snippet{}, "", err
+		}
+		if err == nil {
+			// The snippet with this ID differs in some way; the content
+			// hash cannot identify the new snippet.
+			snippet.ID = ""
+		}
+	} else {
+		var err error
+		if token, err = randomToken(32); err != nil {
+			return models.Snippet{}, "", err
+		}
+		snippet.EditTokenHash = Checksum(token)
+	}
+	if snippet.ID == "" {
+		var err error
+		if snippet.ID, err = randomToken(9); err != nil {
+			return models.Snippet{}, "", err
+		}
+	}
+
+	if err := s.backend.Put(snippet); err != nil {
+		return models.Snippet{}, "", err
+	}
+	return snippet, token, nil
+}
+
+// sameContent reports whether the stored snippet existing can stand for
+// snippet: both are read-only with the same code, neither burns after
+// reading, and they expire within dedupSlack of each other.
+func sameContent(existing, snippet models.Snippet) bool {
+	if existing.EditTokenHash != "" || existing.Code != snippet.Code || existing.Encrypted != snippet.Encrypted {
+		return false
+	}
+	if existing.MaxViews != 0 || snippet.MaxViews != 0 {
+		return false
+	}
+	if existing.ExpiresAt == nil || snippet.ExpiresAt == nil {
+		return existing.ExpiresAt == snippet.ExpiresAt
+	}
+	d := existing.ExpiresAt.Sub(*snippet.ExpiresAt)
+	return d >= -dedupSlack && d <= dedupSlack
+}
+
+// Fork starts a new line of revisions from the snippet id. Forks of
+// encrypted snippets stay encrypted with the same key. Forking counts as a
+// view of the original.
+func (s *Store) Fork(id string) (models.Snippet, string, error) {
+	snippet, err := s.View(id)
+	if err != nil {
+		return models.Snippet{}, "", err
+	}
+	return s.Save(snippet.Code, snippet.ID, Options{Encrypted: snippet.Encrypted})
+}
+
+// Update replaces the code of the snippet id, keeping its ID, if token is its
+// edit token. The code of encrypted snippets must be encrypted again.
+func (s *Store) Update(id string, token string, code string) (models.Snippet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snippet, err := s.authorize(id, token)
+	if err != nil {
+		return models.Snippet{}, err
+	}
+	if err := validateCode(code, snippet.Encrypted); err != nil {
+		return models.Snippet{}, err
+	}
+
+	now := time.Now().UTC()
+	snippet.Code = code
+	snippet.Checksum = Checksum(code)
+	snippet.UpdatedAt = &now
+	if err := s.backend.Put(snippet); err != nil {
+		return models.Snippet{}, err
+	}
+	return snippet, nil
+}
+
+// Delete removes the snippet id if token is its edit token. Revisions based
+// on it are kept.
+func (s *Store) Delete(id string, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.authorize(id, token); err != nil {
+		return err
+	}
+	return s.backend.Delete(id)
+}
+
+// authorize returns the snippet id if token is its edit token.
+func (s *Store) authorize(id string, token string) (models.Snippet, error) {
+	snippet, err := s.Get(id)
+	if err != nil {
+		return models.Snippet{}, err
+	}
+	if snippet.EditTokenHash == "" || subtle.ConstantTimeCompare([]byte(snippet.EditTokenHash), []byte(Checksum(token))) != 1 {
+		return models.Snippet{}, ErrForbidden
+	}
+	return snippet, nil
+}
+
+// Lineage returns the revisions leading to id, oldest first and ending with
+// id itself. Ancestors that no longer exist end the lineage.
+func (s *Store) Lineage(id string) ([]models.Snippet, error) {
+	snippet, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage := []models.Snippet{snippet}
+	for snippet.Parent != "" && len(lineage) < config.MaxSnippetLineage {
+		snippet, err = s.Get(snippet.Parent)
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lineage = append(lineage, snippet)
+	}
+
+	slices.Reverse(lineage)
+	return lineage, nil
+}
+
+// Get returns the snippet id. Expired snippets are deleted on access.
+func (s *Store) Get(id string) (models.Snippet, error) {
+	if !idRe.MatchString(id) {
+		return models.Snippet{}, ErrNotFound
+	}
+
+	snippet, err := s.backend.Get(id)
+	if err != nil {
+		return models.Snippet{}, err
+	}
+	if Expired(snippet, time.Now()) {
+		if err := s.backend.Delete(id); err != nil {
+			log.Printf("Failed to delete expired snippet %s: %v\n", id, err)
+		}
+		return models.Snippet{}, ErrNotFound
+	}
+	if err := Verify(snippet); err != nil {
+		return models.Snippet{}, err
+	}
+	return snippet, nil
+}
+
+// View returns the snippet id for display, counting the view. A snippet
+// reaching its view limit is deleted and cannot be viewed again.
+func (s *Store) View(id string) (models.Snippet, error) {
+	snippet, err := s.Get(id)
+	if err != nil || snippet.MaxViews == 0 {
+		return snippet, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Reload under the lock so that concurrent views are all counted.
+	snippet, err = s.Get(id)
+	if err != nil {
+		return models.Snippet{}, err
+	}
+
+	snippet.Views++
+	if snippet.Views >= snippet.MaxViews {
+		if err := s.backend.Delete(id); err != nil {
+			return models.Snippet{}, err
+		}
+		s.statsMu.Lock()
+		s.stats.Burned++
+		s.statsMu.Unlock()
+		return snippet, nil
+	}
+	if err := s.backend.Put(snippet); err != nil {
+		return models.Snippet{}, err
+	}
+	return snippet, nil
+}
+
+// Private reports whether the server must not reveal the code of snippet
+// outside of a counted view, e.g. in diffs.
+func Private(snippet models.Snippet) bool {
+	return snippet.Encrypted || snippet.MaxViews > 0
+}
+
+// Expired reports whether snippet has expired at now.
+func Expired(snippet models.Snippet, now time.Time) bool {
+	return snippet.ExpiresAt != nil && !now.Before(*snippet.ExpiresAt)
+}
+
+// Verify checks that snippet is intact and within the size limit.
+func Verify(snippet models.Snippet) error {
+	if !idRe.MatchString(snippet.ID) {
+		return fmt.Errorf("%w: invalid ID %q", ErrCorrupt, snippet.ID)
+	}
+	if err := validateCode(snippet.Code, snippet.Encrypted); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrCorrupt, snippet.ID, err)
+	}
+	if snippet.Checksum != Checksum(snippet.Code) {
+		return fmt.Errorf("%w: %s checksum mismatch", ErrCorrupt, snippet.ID)
+	}
+	return nil
+}
+
+// validateCode checks the size of code and, for encrypted snippets, that it
+// looks like a nonce and ciphertext. The plaintext limit applies to the
+// decoded ciphertext less the AES-GCM overhead.
+func validateCode(code string, encrypted bool) error {
+	if !encrypted {
+		if len(code) > config.MaxCodeSize {
+			return ErrTooLarge
+		}
+		return nil
+	}
+
+	if base64.StdEncoding.DecodedLen(len(code)) > config.MaxCodeSize+gcmOverhead+2 {
+		return ErrTooLarge
+	}
+	data, err := base64.StdEncoding.DecodeString(code)
+	if err != nil || len(data) < gcmOverhead {
+		return ErrInvalidCiphertext
+	}
+	if len(data) > config.MaxCodeSize+gcmOverhead {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+func (o Options) ttl() time.Duration {
+	ttl := o.TTL
+	if ttl <= 0 {
+		ttl = config.DefaultSnippetTTLHours * time.Hour
+		if o.Encrypted {
+			ttl = config.PrivateSnippetTTLHours * time.Hour
+		}
+	}
+	if max := config.MaxSnippetTTLHours * time.Hour; ttl > max {
+		ttl = max
+	}
+	return ttl
+}
+
+// randomToken returns n random bytes, encoded for use in URLs.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Close stops the reaper, if running, and closes the backend.
+func (s *Store) Close() error {
+	close(s.done)
+	return s.backend.Close()
+}
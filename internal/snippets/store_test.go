@@ -0,0 +1,225 @@
+package snippets
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+func TestOptionsTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want time.Duration
+	}{
+		{"default", Options{}, config.DefaultSnippetTTLHours * time.Hour},
+		{"encrypted default", Options{Encrypted: true}, config.PrivateSnippetTTLHours * time.Hour},
+		{"explicit", Options{TTL: time.Hour}, time.Hour},
+		{"capped", Options{TTL: (config.MaxSnippetTTLHours + 1) * time.Hour}, config.MaxSnippetTTLHours * time.Hour},
+	}
+	for _, test := range tests {
+		if got := test.opts.ttl(); got != test.want {
+			t.Errorf("%s: ttl() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Second), now.Add(time.Second)
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		want      bool
+	}{
+		{"no expiry", nil, false},
+		{"past", &past, true},
+		{"now", &now, true},
+		{"future", &future, false},
+	}
+	for _, test := range tests {
+		if got := Expired(models.Snippet{ExpiresAt: test.expiresAt}, now); got != test.want {
+			t.Errorf("%s: Expired = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	good := models.Snippet{ID: ID("package main", ""), Code: "package main", Checksum: Checksum("package main")}
+	tests := []struct {
+		name    string
+		modify  func(*models.Snippet)
+		corrupt bool
+	}{
+		{"intact", func(*models.Snippet) {}, false},
+		{"bad ID", func(s *models.Snippet) { s.ID = "../etc" }, true},
+		{"checksum mismatch", func(s *models.Snippet) { s.Code += "\n" }, true},
+		{"invalid ciphertext", func(s *models.Snippet) {
+			s.Encrypted = true
+			s.Checksum = Checksum(s.Code)
+		}, true},
+	}
+	for _, test := range tests {
+		snippet := good
+		test.modify(&snippet)
+		if err := Verify(snippet); errors.Is(err, ErrCorrupt) != test.corrupt {
+			t.Errorf("%s: Verify = %v, want corrupt %v", test.name, err, test.corrupt)
+		}
+	}
+}
+
+func TestSaveDeduplicates(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	defer store.Close()
+
+	first, token, err := store.Save("package main", "", Options{ReadOnly: true})
+	if err != nil || token != "" || first.ID != ID("package main", "") {
+		t.Fatalf("Save = %s, token %q, %v; want the content-hash ID without a token", first.ID, token, err)
+	}
+	again, _, err := store.Save("package main", "", Options{ReadOnly: true})
+	if err != nil || again.ID != first.ID {
+		t.Errorf("saving again = %s, %v; want %s", again.ID, err, first.ID)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"editable", Options{}},
+		{"burnable", Options{ReadOnly: true, MaxViews: 1}},
+		{"other expiry", Options{ReadOnly: true, TTL: time.Hour}},
+	}
+	for _, test := range tests {
+		snippet, _, err := store.Save("package main", "", test.opts)
+		if err != nil || snippet.ID == first.ID {
+			t.Errorf("%s: Save = %s, %v; want a new ID", test.name, snippet.ID, err)
+		}
+	}
+}
+
+// A read-only snippet about to expire is not reused for a longer share.
+func TestSaveDoesNotReuseExpiringSnippet(t *testing.T) {
+	backend := NewMemoryBackend()
+	store := NewStore(backend)
+	defer store.Close()
+
+	soon := time.Now().Add(time.Hour)
+	expiring := models.Snippet{ID: ID("package main", ""), Code: "package main", Checksum: Checksum("package main"), ExpiresAt: &soon}
+	backend.Put(expiring)
+
+	snippet, _, err := store.Save("package main", "", Options{ReadOnly: true})
+	if err != nil || snippet.ID == expiring.ID {
+		t.Errorf("Save = %s, %v; want a new ID", snippet.ID, err)
+	}
+}
+
+// Every editable save gets its own ID and token, so that no one else can
+// edit the link returned to a sharer.
+func TestSaveEditableIsNeverShared(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	defer store.Close()
+
+	original, _, err := store.Save("package main", "", Options{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, firstToken, err := store.Fork(original.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, secondToken, err := store.Fork(original.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == second.ID || firstToken == "" || secondToken == "" || firstToken == secondToken {
+		t.Fatalf("forks %s and %s with tokens %q and %q; want distinct IDs and tokens", first.ID, second.ID, firstToken, secondToken)
+	}
+	if _, err := store.Update(second.ID, firstToken, "package other"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("update with another fork's token = %v, want ErrForbidden", err)
+	}
+	if _, err := store.Update(second.ID, secondToken, "package other"); err != nil {
+		t.Errorf("update with the fork's token = %v", err)
+	}
+	if _, err := store.Update(original.ID, firstToken, "package other"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("update of a read-only snippet = %v, want ErrForbidden", err)
+	}
+}
+
+func TestViewBurnsAfterMaxViews(t *testing.T) {
+	store := NewStore(NewMemoryBackend())
+	defer store.Close()
+
+	snippet, _, err := store.Save("package main", "", Options{MaxViews: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		viewed, err := store.View(snippet.ID)
+		if err != nil || viewed.Views != i {
+			t.Fatalf("view %d = %d views, %v", i, viewed.Views, err)
+		}
+	}
+	if _, err := store.View(snippet.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("view after burning = %v, want ErrNotFound", err)
+	}
+	if burned := store.Stats().Burned; burned != 1 {
+		t.Errorf("Burned = %d, want 1", burned)
+	}
+}
+
+func TestGetDeletesExpired(t *testing.T) {
+	backend := NewMemoryBackend()
+	store := NewStore(backend)
+	defer store.Close()
+
+	past := time.Now().Add(-time.Minute)
+	snippet := models.Snippet{ID: ID("package main", ""), Code: "package main", Checksum: Checksum("package main"), ExpiresAt: &past}
+	backend.Put(snippet)
+
+	if _, err := store.Get(snippet.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of an expired snippet = %v, want ErrNotFound", err)
+	}
+	if _, err := backend.Get(snippet.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired snippet was not deleted: %v", err)
+	}
+}
+
+func TestReapSkipsCorruptRecords(t *testing.T) {
+	backend, err := NewFilesystemBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(backend)
+	defer store.Close()
+
+	past := time.Now().Add(-time.Minute)
+	for _, code := range []string{"package a", "package b"} {
+		expired := models.Snippet{ID: ID(code, ""), Code: code, Checksum: Checksum(code), ExpiresAt: &past}
+		if err := backend.Put(expired); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kept, _, err := store.Save("package c", "", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(backend.path("badbadbadbad"), []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := store.Reap()
+	if err != nil || deleted != 2 {
+		t.Fatalf("Reap = %d, %v; want 2 deleted", deleted, err)
+	}
+	stats := store.Stats()
+	if stats.Snippets != 1 || stats.Corrupt != 1 || stats.Reaped != 2 {
+		t.Errorf("stats = %+v; want 1 snippet, 1 corrupt, 2 reaped", stats)
+	}
+	if _, err := store.Get(kept.ID); err != nil {
+		t.Errorf("unexpired snippet was lost: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/types"
+	"sort"
+	"sync"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// lockedImporter serializes imports so that one importer, and the packages
+// it has already loaded, can be shared by concurrent checks.
+type lockedImporter struct {
+	mu       sync.Mutex
+	importer types.Importer
+}
+
+func (i *lockedImporter) Import(path string) (*types.Package, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.importer.Import(path)
+}
+
+var stdImporter = &lockedImporter{importer: importer.Default()}
+
+// CheckCode parses and type checks code in process and returns every syntax
+// and type error, including unused variables and imports. Type checking is
+// skipped when the code does not parse.
+func CheckCode(code string) []models.SourceError {
+	fset, file, err := parseCode(code)
+	if err != nil {
+		return syntaxErrors(err)
+	}
+
+	var errors []models.SourceError
+	conf := types.Config{
+		Importer:  stdImporter,
+		GoVersion: config.GoVersion,
+		Error: func(err error) {
+			typeErr, ok := err.(types.Error)
+			if !ok {
+				errors = append(errors, models.SourceError{Message: err.Error(), Kind: "type"})
+				return
+			}
+			pos := fset.Position(typeErr.Pos)
+			errors = append(errors, models.SourceError{
+				Line:    pos.Line,
+				Column:  pos.Column,
+				Message: typeErr.Msg,
+				Kind:    "type",
+			})
+		},
+	}
+	conf.Check("main", fset, []*ast.File{file}, nil)
+
+	sort.SliceStable(errors, func(i, j int) bool {
+		if errors[i].Line != errors[j].Line {
+			return errors[i].Line < errors[j].Line
+		}
+		return errors[i].Column < errors[j].Column
+	})
+	return errors
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+
+	"github.com/AlexandruC0909/playground/internal/models"
+)
+
+// SyntaxErrors parses code and returns all of its syntax errors. If there are
+// any, it also returns code with each top-level declaration that is free of
+// errors formatted, or nil if nothing could be formatted.
+func SyntaxErrors(code []byte) ([]models.SourceError, []byte) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, parser.ParseComments|parser.AllErrors)
+	if err == nil {
+		return nil, nil
+	}
+
+	errors := syntaxErrors(err)
+	list, ok := err.(scanner.ErrorList)
+	if !ok || file == nil {
+		return errors, nil
+	}
+	return errors, formatCleanDecls(fset, file, code, list)
+}
+
+// syntaxErrors converts an error returned by the parser.
+func syntaxErrors(err error) []models.SourceError {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return []models.SourceError{{Message: err.Error(), Kind: "syntax"}}
+	}
+
+	var errors []models.SourceError
+	for _, e := range list {
+		sourceError := models.SourceError{
+			Line:    e.Pos.Line,
+			Column:  e.Pos.Column,
+			Message: e.Msg,
+			Kind:    "syntax",
+		}
+		// Recovery at the end of the file tends to repeat the same error.
+		if n := len(errors); n > 0 && errors[n-1] == sourceError {
+			continue
+		}
+		errors = append(errors, sourceError)
+	}
+	return errors
+}
+
+// formatCleanDecls formats the declarations of file that contain none of
+// errs, splicing them into code in place of their original text.
+func formatCleanDecls(fset *token.FileSet, file *ast.File, code []byte, errs scanner.ErrorList) []byte {
+	tokFile := fset.File(file.Pos())
+	var out bytes.Buffer
+	last := 0
+	changed := false
+
+	for _, decl := range file.Decls {
+		start, end := tokFile.Offset(declPos(decl)), tokFile.Offset(decl.End())
+		if start < last || end > len(code) || hasBadNode(decl) || errorInRange(errs, start, end) {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := format.Node(&buf, fset, &printer.CommentedNode{Node: decl, Comments: file.Comments}); err != nil {
+			continue
+		}
+		if bytes.Equal(buf.Bytes(), code[start:end]) {
+			continue
+		}
+
+		out.Write(code[last:start])
+		out.Write(buf.Bytes())
+		last = end
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	out.Write(code[last:])
+	return out.Bytes()
+}
+
+// declPos returns where decl starts, including its doc comment.
+func declPos(decl ast.Decl) token.Pos {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	}
+	return decl.Pos()
+}
+
+func errorInRange(errs scanner.ErrorList, start, end int) bool {
+	for _, e := range errs {
+		if e.Pos.Offset >= start && e.Pos.Offset <= end {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBadNode(node ast.Node) bool {
+	bad := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.BadDecl, *ast.BadExpr, *ast.BadStmt:
+			bad = true
+		}
+		return !bad
+	})
+	return bad
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AlexandruC0909/playground/internal/config"
+)
+
+// DecodeCode decodes the code query parameter of the home page: the program
+// compressed with raw DEFLATE and encoded as unpadded base64url. Both the
+// parameter and the decompressed code are size limited, so a link cannot
+// make the server inflate arbitrarily large input.
+func DecodeCode(param string) (string, error) {
+	if len(param) > config.MaxCodeParamSize {
+		return "", fmt.Errorf("code parameter too large: %d bytes, at most %d", len(param), config.MaxCodeParamSize)
+	}
+
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(param, "="))
+	if err != nil {
+		return "", fmt.Errorf("invalid code parameter: %v", err)
+	}
+
+	reader := flate.NewReader(bytes.NewReader(compressed))
+	defer reader.Close()
+
+	code, err := io.ReadAll(io.LimitReader(reader, config.MaxCodeSize+1))
+	if err != nil {
+		return "", fmt.Errorf("invalid code parameter: %v", err)
+	}
+	if len(code) > config.MaxCodeSize {
+		return "", fmt.Errorf("code too large, at most %d bytes", config.MaxCodeSize)
+	}
+	if !utf8.Valid(code) {
+		return "", fmt.Errorf("invalid code parameter: code is not valid UTF-8")
+	}
+	return string(code), nil
+}
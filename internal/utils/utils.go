@@ -65,9 +65,15 @@ func ValidateAndPrepare(code string, session *models.ProgramSession) error {
 	return nil
 }
 
-func detectInputOperations(code string) ([]models.InputOperation, error) {
+// parseCode parses the submitted program as main.go.
+func parseCode(code string) (*token.FileSet, *ast.File, error) {
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, "main.go", code, parser.AllErrors)
+	return fset, file, err
+}
+
+func detectInputOperations(code string) ([]models.InputOperation, error) {
+	fset, file, err := parseCode(code)
 	if err != nil {
 		return nil, err
 	}
@@ -142,12 +148,8 @@ func ExtractIP(r *http.Request) string {
 	return r.RemoteAddr
 }
 
-func ParseRequestBody(r *http.Request) (struct {
-	Code string `json:"code"`
-}, error) {
-	var requestData struct {
-		Code string `json:"code"`
-	}
+func ParseRequestBody(r *http.Request) (models.CodeRequest, error) {
+	var requestData models.CodeRequest
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
 		return requestData, fmt.Errorf("error decoding JSON: %v", err)
 	}
@@ -169,9 +171,15 @@ func LogTiming(operation string, start time.Time) {
 	log.Printf("%s took: %v\n", operation, time.Since(start))
 }
 
+// SendError ends the output of session with errMsg, reporting it to the
+// session's Observer first.
 func SendError(session *models.ProgramSession, errMsg string) {
-	session.OutputChan <- models.ProgramOutput{
+	output := models.ProgramOutput{
 		Error: errMsg,
 		Done:  true,
 	}
+	if session.Observer != nil {
+		session.Observer(output)
+	}
+	session.OutputChan <- output
 }
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateGoCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"hello", "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hi\") }\n", true},
+		{"go func", "package main\n\nfunc main() {\n\tn := 0\n\tgo func() { n++ }()\n\tn++\n}\n", true},
+		{"named goroutine", "package main\n\nfunc f() {}\n\nfunc main() { go f() }\n", true},
+		{"os/exec", "package main\n\nimport \"os/exec\"\n\nfunc main() { exec.Command(\"ls\").Run() }\n", false},
+		{"unsafe", "package main\n\nimport \"unsafe\"\n\nfunc main() { _ = unsafe.Sizeof(0) }\n", false},
+		{"file write", "package main\n\nimport \"os\"\n\nfunc main() { os.Create(\"x\") }\n", false},
+	}
+	for _, test := range tests {
+		if got := validateGoCode(test.code); got != test.want {
+			t.Errorf("%s: validateGoCode = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// Every bundled example must be accepted, or selftest and the examples menu
+// break.
+func TestValidateGoCodeAcceptsExamples(t *testing.T) {
+	files, err := filepath.Glob("../../examples/*.go")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no examples found: %v", err)
+	}
+	for _, file := range files {
+		code, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !validateGoCode(string(code)) {
+			t.Errorf("%s is rejected", filepath.Base(file))
+		}
+	}
+}
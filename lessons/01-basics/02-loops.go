@@ -0,0 +1,15 @@
+//go:build ignore
+
+package main
+
+import "fmt"
+
+func sum(n int) int {
+	total := 0
+	// Add the numbers from 1 to n to total.
+	return total
+}
+
+func main() {
+	fmt.Println(sum(10))
+}
@@ -0,0 +1,14 @@
+//go:build ignore
+
+package main
+
+import "fmt"
+
+func reverse(s string) string {
+	// Return s with its characters in reverse order.
+	return s
+}
+
+func main() {
+	fmt.Println(reverse("Gopher"))
+}
@@ -0,0 +1,28 @@
+//go:build ignore
+
+package main
+
+import "testing"
+
+func TestReverseASCII(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"Gopher", "rehpoG"},
+		{"ab", "ba"},
+		{"", ""},
+	} {
+		if got := reverse(tt.in); got != tt.want {
+			t.Errorf("reverse(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReverseUnicode(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"héllo", "olléh"},
+		{"日本語", "語本日"},
+	} {
+		if got := reverse(tt.in); got != tt.want {
+			t.Errorf("reverse(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
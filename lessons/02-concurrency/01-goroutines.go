@@ -0,0 +1,15 @@
+//go:build ignore
+
+package main
+
+import "fmt"
+
+func work(id int) {
+	fmt.Printf("worker %d done\n", id)
+}
+
+func main() {
+	for i := 1; i <= 5; i++ {
+		go work(i)
+	}
+}
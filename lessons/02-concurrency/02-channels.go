@@ -0,0 +1,17 @@
+//go:build ignore
+
+package main
+
+import "fmt"
+
+func square(n int, results chan int) {
+	// Send n*n on results.
+}
+
+func main() {
+	results := make(chan int)
+	for i := 1; i <= 5; i++ {
+		go square(i, results)
+		// Receive and print the square of i.
+	}
+}
@@ -0,0 +1,360 @@
+// Classroom rooms. Students work in their own editor, which reports their
+// code and runs to the room; the instructor's dashboard follows every student
+// and pushes code to all of them. Tokens are kept in localStorage per room.
+
+const CODE_UPDATE_DELAY = 1500;
+
+function roomStorageKey(code, role) {
+  return `room:${code}:${role}`;
+}
+
+async function createRoom() {
+  const error = document.getElementById("room-error");
+  error.textContent = "";
+
+  try {
+    const response = await fetch("/rooms", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({}),
+    });
+    if (!response.ok) {
+      throw new Error(await response.text());
+    }
+
+    const room = await response.json();
+    localStorage.setItem(roomStorageKey(room.code, "instructor"), room.instructorToken);
+    window.location.href = room.dashboardUrl;
+  } catch (err) {
+    error.textContent = err.message;
+  }
+}
+
+function joinRoom(event) {
+  event.preventDefault();
+  const code = document.getElementById("room-code").value.trim().toUpperCase();
+  if (code) {
+    window.location.href = `/classroom/${encodeURIComponent(code)}`;
+  }
+}
+
+// Appends an element with text to parent. Text from students is never
+// parsed as HTML.
+function appendText(parent, tag, text, className = "") {
+  const element = document.createElement(tag);
+  element.textContent = text;
+  if (className) element.className = className;
+  parent.appendChild(element);
+  return element;
+}
+
+class RoomStream {
+  constructor(code, token, onEvent, onClosed) {
+    this.source = new EventSource(
+      `/rooms/${encodeURIComponent(code)}/events?token=${encodeURIComponent(token)}`
+    );
+    this.source.onmessage = (event) => {
+      const data = JSON.parse(event.data);
+      if (data.type === "closed") {
+        this.source.close();
+        onClosed("The room was closed.");
+        return;
+      }
+      onEvent(data);
+    };
+    // EventSource reconnects by itself unless the server refused it.
+    this.source.onerror = () => {
+      if (this.source.readyState === EventSource.CLOSED) {
+        onClosed("Disconnected from the room.");
+      }
+    };
+  }
+}
+
+class RoomStudentView {
+  constructor(app, code) {
+    this.app = app;
+    this.code = code;
+    this.starterCode = app.editor.getValue();
+    this.status = document.getElementById("room-status");
+    this.broadcasts = document.getElementById("room-broadcasts");
+    this.updateTimer = null;
+    this.join();
+  }
+
+  async join() {
+    let student = JSON.parse(localStorage.getItem(roomStorageKey(this.code, "student")) || "null");
+
+    try {
+      if (!student) {
+        const name = window.prompt("Your name, as your instructor will see it:");
+        if (!name) {
+          this.status.textContent = "Reload the page to join the room.";
+          return;
+        }
+
+        const response = await fetch(`/rooms/${encodeURIComponent(this.code)}/join`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ name }),
+        });
+        if (!response.ok) {
+          throw new Error(await response.text());
+        }
+
+        const joined = await response.json();
+        student = { id: joined.studentId, token: joined.studentToken, name };
+        localStorage.setItem(roomStorageKey(this.code, "student"), JSON.stringify(student));
+      }
+    } catch (error) {
+      this.status.textContent = `Could not join: ${error.message}`;
+      return;
+    }
+
+    this.token = student.token;
+    this.status.textContent = `Joined as ${student.name}.`;
+    this.app.state.runHeaders = { "X-Room": this.code, "X-Student-Token": this.token };
+    this.app.editor.session.on("change", () => this.scheduleUpdate());
+    this.stream = new RoomStream(
+      this.code,
+      this.token,
+      (event) => this.handleEvent(event),
+      (message) => this.leave(message)
+    );
+  }
+
+  leave(message) {
+    this.status.textContent = message;
+    this.app.state.runHeaders = {};
+    clearTimeout(this.updateTimer);
+    this.token = null;
+  }
+
+  scheduleUpdate() {
+    clearTimeout(this.updateTimer);
+    this.updateTimer = setTimeout(() => this.sendCode(), CODE_UPDATE_DELAY);
+  }
+
+  async sendCode() {
+    if (!this.token) return;
+
+    const response = await fetch(`/rooms/${encodeURIComponent(this.code)}/code`, {
+      method: "PUT",
+      headers: { "Content-Type": "application/json", "X-Student-Token": this.token },
+      body: JSON.stringify({ code: this.app.editor.getValue() }),
+    });
+    if (response.status === 403 || response.status === 404) {
+      localStorage.removeItem(roomStorageKey(this.code, "student"));
+      this.leave("You are no longer in this room. Reload the page to join again.");
+    }
+  }
+
+  handleEvent(event) {
+    switch (event.type) {
+      case "room":
+        this.starterCode = event.room.starterCode;
+        this.broadcasts.innerHTML = "";
+        for (const broadcast of event.room.broadcasts) {
+          this.showBroadcast(broadcast);
+        }
+        if (event.room.broadcasts.length === 0) {
+          appendText(this.broadcasts, "p", "Nothing yet.");
+        }
+        break;
+      case "starter":
+        this.receiveStarter(event.code);
+        break;
+      case "broadcast":
+        if (this.broadcasts.querySelector(".room-broadcast") === null) {
+          this.broadcasts.innerHTML = "";
+        }
+        this.showBroadcast(event.broadcast);
+        break;
+    }
+  }
+
+  // New starter code replaces the editor's, unless the student changed it.
+  receiveStarter(code) {
+    const edited = this.app.editor.getValue() !== this.starterCode;
+    this.starterCode = code;
+    if (!edited || window.confirm("Your instructor pushed new starter code. Replace your code with it?")) {
+      this.app.editor.setValue(code, -1);
+    }
+  }
+
+  showBroadcast(broadcast) {
+    const item = document.createElement("div");
+    item.className = "room-broadcast";
+    if (broadcast.message) appendText(item, "p", broadcast.message);
+    appendText(item, "pre", broadcast.code);
+    const open = appendText(item, "button", "Open in editor", "button-1 button-reset");
+    open.onclick = () => this.app.editor.setValue(broadcast.code, -1);
+    this.broadcasts.prepend(item);
+  }
+
+  reset() {
+    this.app.editor.setValue(this.starterCode, -1);
+    this.app.cleanupPreviousSession();
+  }
+}
+
+class RoomDashboard {
+  constructor(app, code) {
+    this.app = app;
+    this.code = code;
+    this.token = localStorage.getItem(roomStorageKey(code, "instructor"));
+    this.status = document.getElementById("room-status");
+    this.list = document.getElementById("room-students");
+    this.students = new Map();
+
+    if (!this.token) {
+      this.status.textContent = "Only the browser that created this room can manage it.";
+      return;
+    }
+    this.stream = new RoomStream(
+      code,
+      this.token,
+      (event) => this.handleEvent(event),
+      (message) => (this.status.textContent = message)
+    );
+  }
+
+  handleEvent(event) {
+    switch (event.type) {
+      case "room":
+        this.students.clear();
+        for (const student of event.room.students || []) {
+          this.students.set(student.id, student);
+        }
+        this.render();
+        break;
+      case "student":
+        this.students.set(event.student.id, event.student);
+        this.render();
+        break;
+    }
+  }
+
+  render() {
+    const count = this.students.size;
+    this.status.textContent = `${count} student${count === 1 ? "" : "s"} in the room.`;
+
+    // Keep the code sections the instructor opened open.
+    const open = new Set(
+      [...this.list.querySelectorAll("details[open]")].map((details) => details.dataset.student)
+    );
+    this.list.innerHTML = "";
+
+    const students = [...this.students.values()].sort((a, b) => a.name.localeCompare(b.name));
+    for (const student of students) {
+      const card = document.createElement("div");
+      card.className = "room-student";
+      appendText(card, "h3", student.name);
+      appendText(card, "p", this.describeRun(student.run), `room-run ${this.runClass(student.run)}`);
+
+      const details = document.createElement("details");
+      details.dataset.student = student.id;
+      details.open = open.has(student.id);
+      appendText(details, "summary", `Code, updated ${new Date(student.updatedAt).toLocaleTimeString()}`);
+      appendText(details, "pre", student.code);
+      if (student.run && (student.run.output || student.run.error)) {
+        appendText(details, "pre", student.run.output + (student.run.error || ""), "room-output");
+      }
+      const openButton = appendText(details, "button", "Open in editor", "button-1 button-reset");
+      openButton.onclick = () => this.app.editor.setValue(student.code, -1);
+      card.appendChild(details);
+
+      this.list.appendChild(card);
+    }
+  }
+
+  describeRun(run) {
+    if (!run) return "Has not run the code yet";
+    const started = new Date(run.startedAt).toLocaleTimeString();
+    if (!run.finishedAt) return `Running since ${started}`;
+    return run.error ? `Failed at ${started}` : `Ran successfully at ${started}`;
+  }
+
+  runClass(run) {
+    if (!run || !run.finishedAt) return "";
+    return run.error ? "error" : "success";
+  }
+
+  async send(path, method, body) {
+    try {
+      const response = await fetch(`/rooms/${encodeURIComponent(this.code)}${path}`, {
+        method,
+        headers: { "Content-Type": "application/json", "X-Instructor-Token": this.token || "" },
+        body: body ? JSON.stringify(body) : undefined,
+      });
+      if (!response.ok) {
+        throw new Error(await response.text());
+      }
+      return true;
+    } catch (error) {
+      this.app.handleError(error);
+      return false;
+    }
+  }
+
+  async pushStarter() {
+    if (await this.send("/starter", "PUT", { code: this.app.editor.getValue() })) {
+      this.status.textContent = "Starter code pushed to every student.";
+    }
+  }
+
+  async broadcast() {
+    const message = window.prompt("Message to send with the code (optional):");
+    if (message === null) return;
+    if (await this.send("/broadcast", "POST", { code: this.app.editor.getValue(), message })) {
+      this.status.textContent = "Code broadcast to every student.";
+    }
+  }
+
+  // Lists the groups of students whose code looks copied from each other.
+  async checkSimilarity() {
+    const panel = document.getElementById("room-similarity");
+    try {
+      const response = await fetch(`/rooms/${encodeURIComponent(this.code)}/similarity`, {
+        headers: { "X-Instructor-Token": this.token || "" },
+      });
+      if (!response.ok) {
+        throw new Error(await response.text());
+      }
+      const report = await response.json();
+
+      panel.innerHTML = "";
+      panel.className = "room-similarity";
+      appendText(panel, "h3", "Similarity");
+      if (report.clusters.length === 0) {
+        appendText(panel, "p", `No similar code among ${report.compared} students.`);
+      }
+      for (const cluster of report.clusters) {
+        const score = Math.round(cluster.score * 100);
+        appendText(panel, "p", `${cluster.members.join(", ")}: up to ${score}% similar`, "room-cluster");
+      }
+      for (const skip of report.skipped || []) {
+        appendText(panel, "p", `${skip.name} not compared: ${skip.reason}`);
+      }
+    } catch (error) {
+      this.app.handleError(error);
+    }
+  }
+
+  async closeRoom() {
+    if (!window.confirm("Close the room? Students will be disconnected.")) return;
+    if (await this.send("", "DELETE")) {
+      localStorage.removeItem(roomStorageKey(this.code, "instructor"));
+      window.location.href = "/classroom";
+    }
+  }
+}
+
+let roomApp = null;
+const roomEditor = document.getElementById("editor");
+if (roomEditor && roomEditor.dataset.room) {
+  roomApp =
+    roomEditor.dataset.role === "instructor"
+      ? new RoomDashboard(editorApp, roomEditor.dataset.room)
+      : new RoomStudentView(editorApp, roomEditor.dataset.room);
+}
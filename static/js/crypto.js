@@ -0,0 +1,64 @@
+function bytesToBase64(bytes) {
+  let binary = "";
+  for (const byte of bytes) {
+    binary += String.fromCharCode(byte);
+  }
+  return btoa(binary);
+}
+
+function base64ToBytes(text) {
+  return Uint8Array.from(atob(text), (c) => c.charCodeAt(0));
+}
+
+// Encrypts code with a fresh AES-GCM key. The ciphertext (nonce followed by
+// the encrypted code) goes to the server; the key only ever lives in the
+// link's fragment.
+async function encryptCode(code) {
+  const key = await crypto.subtle.generateKey(
+    { name: "AES-GCM", length: 256 },
+    true,
+    ["encrypt", "decrypt"]
+  );
+  const iv = crypto.getRandomValues(new Uint8Array(12));
+  const encrypted = new Uint8Array(
+    await crypto.subtle.encrypt(
+      { name: "AES-GCM", iv },
+      key,
+      new TextEncoder().encode(code)
+    )
+  );
+
+  const payload = new Uint8Array(iv.length + encrypted.length);
+  payload.set(iv);
+  payload.set(encrypted, iv.length);
+
+  const rawKey = new Uint8Array(await crypto.subtle.exportKey("raw", key));
+  const keyText = bytesToBase64(rawKey)
+    .replace(/\+/g, "-")
+    .replace(/\//g, "_")
+    .replace(/=+$/, "");
+
+  return { ciphertext: bytesToBase64(payload), key: keyText };
+}
+
+async function decryptCode(ciphertext, keyText) {
+  let keyBase64 = keyText.replace(/-/g, "+").replace(/_/g, "/");
+  while (keyBase64.length % 4) {
+    keyBase64 += "=";
+  }
+
+  const key = await crypto.subtle.importKey(
+    "raw",
+    base64ToBytes(keyBase64),
+    "AES-GCM",
+    false,
+    ["decrypt"]
+  );
+  const payload = base64ToBytes(ciphertext);
+  const decrypted = await crypto.subtle.decrypt(
+    { name: "AES-GCM", iv: payload.slice(0, 12) },
+    key,
+    payload.slice(12)
+  );
+  return new TextDecoder().decode(decrypted);
+}
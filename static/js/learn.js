@@ -0,0 +1,82 @@
+// Lesson pages reuse the editor from script.js and add exercise checking.
+// Hints are revealed one per failed check. Exercises graded by hidden tests
+// also report each test and a score.
+class Lesson {
+  constructor(app) {
+    this.app = app;
+    this.starterCode = app.editor.getValue();
+    this.checkUrl = document.getElementById("editor").dataset.checkUrl;
+    this.failedChecks = 0;
+  }
+
+  reset() {
+    this.app.editor.setValue(this.starterCode, -1);
+    this.app.cleanupPreviousSession();
+  }
+
+  async check() {
+    if (!this.checkUrl) return;
+
+    const output = this.app.outputDiv;
+    this.app.cleanupPreviousSession();
+    output.innerHTML = `<div class="output-line">Checking...</div>`;
+
+    try {
+      const response = await fetch(this.checkUrl, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ code: this.app.editor.getValue() }),
+      });
+
+      if (!response.ok) {
+        throw new Error(await response.text());
+      }
+
+      this.showResult(await response.json());
+    } catch (error) {
+      this.app.handleError(error);
+    }
+  }
+
+  showResult(result) {
+    const output = this.app.outputDiv;
+    output.innerHTML = "";
+
+    const line = (text, className = "output-line") => {
+      const div = document.createElement("div");
+      div.className = className;
+      div.textContent = text;
+      output.appendChild(div);
+    };
+
+    if (result.output) line(result.output);
+    if (result.error) line(result.error, "error");
+
+    for (const test of result.tests || []) {
+      line(`${test.passed ? "✓" : "✗"} ${test.name}`, test.passed ? "output-line" : "error");
+      if (test.output) line(test.output, "output-line lesson-test-output");
+    }
+    if (result.score != null) {
+      line(`Score: ${Math.round(result.score * 100)}%`);
+    }
+
+    if (result.passed) {
+      output.classList.add("success");
+      line("Well done, all checks passed!", "output-line finished-program");
+      return;
+    }
+
+    output.classList.add("error");
+    for (const failure of result.failures || []) {
+      line(`✗ ${failure}`, "error");
+    }
+
+    const hints = result.hints || [];
+    if (hints.length > 0) {
+      line(`Hint: ${hints[Math.min(this.failedChecks, hints.length - 1)]}`, "output-line lesson-hint");
+    }
+    this.failedChecks++;
+  }
+}
+
+const lessonApp = new Lesson(editorApp);
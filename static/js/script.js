@@ -20,10 +20,13 @@ const KEYBINDINGS = {
 
 class EditorState {
   constructor() {
-    this.currentExample = 1;
+    this.currentExample = document.getElementById("editor").dataset.example || "";
     this.currentSessionId = null;
     this.currentEventSource = null;
     this.currentInputHandler = null;
+    this.snippetId = document.getElementById("editor").dataset.snippetId || "";
+    // Extra headers sent with runs, like a classroom student's token.
+    this.runHeaders = {};
   }
 }
 
@@ -48,10 +51,40 @@ class Editor {
     this.configureEditor();
     this.setupCommands();
     this.setupDropdownEvents();
+    this.decryptSnippet();
+
+    // Embedded snippets are read-only and must not steal focus from the
+    // page framing them; editing happens in the full playground.
+    if (document.getElementById("editor").dataset.readOnly) {
+      this.editor.setReadOnly(true);
+      const editLink = document.getElementById("button-edit");
+      if (editLink) {
+        editLink.href += window.location.hash;
+      }
+      return;
+    }
+
     this.editor.focus();
     this.editor.navigateFileEnd();
   }
 
+  async decryptSnippet() {
+    const ciphertext = document.getElementById("editor").dataset.ciphertext;
+    if (!ciphertext) return;
+
+    const keyText = window.location.hash.slice(1);
+    if (!keyText) {
+      this.handleError(new Error("This snippet is encrypted and the link has no key"));
+      return;
+    }
+
+    try {
+      this.editor.setValue(await decryptCode(ciphertext, keyText), -1);
+    } catch (error) {
+      this.handleError(new Error("Could not decrypt this snippet with the key in the link"));
+    }
+  }
+
   configureEditor() {
     Object.entries(EDITOR_CONFIG).forEach(([key, value]) => {
       if (key === "mode") {
@@ -80,6 +113,7 @@ class Editor {
   setupDropdownEvents() {
     const dropdown = document.querySelector(".dropdown");
     const dropdownContent = document.querySelector(".dropdown-content");
+    if (!dropdown) return;
 
     dropdown.addEventListener(
       "mouseenter",
@@ -184,6 +218,68 @@ class Editor {
     }
   }
 
+  async shareCode(encrypted = false) {
+    let code = this.editor.getValue();
+    let fragment = "";
+
+    try {
+      if (encrypted) {
+        const { ciphertext, key } = await encryptCode(code);
+        code = ciphertext;
+        fragment = `#${key}`;
+      }
+
+      const response = await fetch("/share", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ code, parent: this.state.snippetId, encrypted }),
+      });
+
+      if (!response.ok) {
+        throw new Error(await response.text());
+      }
+
+      const { id, url, editToken } = await response.json();
+      this.state.snippetId = id;
+      const link = new URL(url + fragment, window.location.origin).href;
+      window.history.pushState(null, "", url + fragment);
+      this.outputDiv.classList.remove("error", "invalid");
+      this.outputDiv.innerHTML = `<div class="output-line">Shared at <a href="${link}">${link}</a></div>`;
+      if (editToken) {
+        localStorage.setItem(`editToken:${id}`, editToken);
+        this.outputDiv.innerHTML += `<div class="output-line">Edit token (keep it to update or delete this link): ${editToken}</div>`;
+      }
+      if (navigator.clipboard) {
+        navigator.clipboard.writeText(link).catch(() => {});
+      }
+    } catch (error) {
+      this.handleError(error);
+    }
+  }
+
+  async downloadCode() {
+    try {
+      const response = await fetch("/export", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ code: this.editor.getValue() }),
+      });
+
+      if (!response.ok) {
+        throw new Error(await response.text());
+      }
+
+      const url = URL.createObjectURL(await response.blob());
+      const link = document.createElement("a");
+      link.href = url;
+      link.download = "playground.zip";
+      link.click();
+      URL.revokeObjectURL(url);
+    } catch (error) {
+      this.handleError(error);
+    }
+  }
+
   async runCode() {
     this.cleanupPreviousSession();
     const code = this.editor.getValue();
@@ -194,6 +290,7 @@ class Editor {
         headers: {
           "Content-Type": "application/json",
           "X-Previous-Session": this.state.currentSessionId || "",
+          ...this.state.runHeaders,
         },
         body: JSON.stringify({ code }),
       });
@@ -378,349 +475,26 @@ class Editor {
     this.inputSection.classList.add("display-none");
   }
 
-  resetCode(example) {
+  async resetCode(example) {
     const output = document.getElementById("output");
     output.classList.remove("error");
     output.classList.remove("success");
     output.classList.remove("invalid");
     output.textContent = "";
-    switch (example) {
-      case 1:
-        this.editor.setValue(
-          `package main
-  
-  import "fmt"
-  
-  func main() {
-      fmt.Println("Hello, World!")
-  }`,
-          -1
-        );
-        break;
-      case 2:
-        this.editor.setValue(
-          `package main
-
-import "fmt"
-
-func fibonacci(n int) {
-    a, b := 0, 1
-    fmt.Printf("Fibonacci(%d) = %d\\n", 0, a)
-    if n == 0 {
-        return
-    }
-    fmt.Printf("Fibonacci(%d) = %d\\n", 1, b)
-    for i := 2; i <= n; i++ {
-        a, b = b, a+b
-        fmt.Printf("Fibonacci(%d) = %d\\n", i, b)
-    }
-}
+    if (!example) return;
 
-func main() {
-    n := 20
-    fibonacci(n)
-}`,
-          -1
-        );
-        break;
-      case 3:
-        this.editor.setValue(
-          `package main
-  
-  import (
-      "fmt"
-      "math/rand"
-      "time"
-  )
-  
-  func bubbleSort(arr []int) {
-      n := len(arr)
-      for i := 0; i < n-1; i++ {
-          swapped := false
-          for j := 0; j < n-i-1; j++ {
-              if arr[j] > arr[j+1] {
-                  arr[j], arr[j+1] = arr[j+1], arr[j]
-                  swapped = true
-              }
-          }
-          if !swapped {
-              break
-          }
-      }
-  }
-  
-  func main() {
-      rand.Seed(time.Now().UnixNano())
-  
-      arr := make([]int, 30)
-  
-      for i := 0; i < 30; i++ {
-          arr[i] = rand.Intn(101)
-      }
-  
-      fmt.Println("Unsorted array:", arr)
-  
-      bubbleSort(arr)
-  
-      fmt.Println("Sorted array:", arr)
-  }`,
-          -1
-        );
-        break;
-      case 4:
-        this.editor.setValue(
-          `package main
-  
-  import (
-      "fmt"
-      "sync"
-      "time"
-  )
-  
-  func calculate(n int, calcFunc func(int) int, ch chan int, wg *sync.WaitGroup) {
-      defer wg.Done()
-      time.Sleep(time.Second)
-      ch <- calcFunc(n)
-  }
-  
-  func main() {
-      var wg sync.WaitGroup
-      squareChan := make(chan int)
-      cubeChan := make(chan int)
-  
-      number := 3
-  
-      wg.Add(2)
-      go calculate(number, func(n int) int { return n * n }, squareChan, &wg)
-      go calculate(number, func(n int) int { return n * n * n }, cubeChan, &wg)
-  
-      go func() {
-          wg.Wait()
-          close(squareChan)
-          close(cubeChan)
-      }()
-  
-      squareResult, ok := <-squareChan
-      if !ok {
-          fmt.Println("Failed to receive square result")
-      } else {
-          fmt.Printf("Square: %d\\n", squareResult)
-      }
-  
-      cubeResult, ok := <-cubeChan
-      if !ok {
-          fmt.Println("Failed to receive cube result")
-      } else {
-          fmt.Printf("Cube: %d\\n", cubeResult)
-      }
-  }
-  `,
-          -1
-        );
-        break;
-      case 5:
-        this.editor.setValue(
-          `package main
-  
-  import (
-      "fmt"
-  )
-  
-  func multiplyMatrices(a, b [][]int) [][]int {
-      rowsA, colsA := len(a), len(a[0])
-      _, colsB := len(b), len(b[0])
-  
-      result := make([][]int, rowsA)
-      for i := range result {
-          result[i] = make([]int, colsB)
-      }
-  
-      for i := 0; i < rowsA; i++ {
-          for j := 0; j < colsB; j++ {
-              for k := 0; k < colsA; k++ {
-                  result[i][j] += a[i][k] * b[k][j]
-              }
-          }
-      }
-  
-      return result
-  }
-  
-  func main() {
-      a := [][]int{
-          {1, 2},
-          {3, 4},
-      }
-      b := [][]int{
-          {5, 6},
-          {7, 8},
-      }
-  
-      result := multiplyMatrices(a, b)
-      fmt.Println("Result of matrix multiplication:")
-      for _, row := range result {
-          fmt.Println(row)
-      }
-  }
-  
-  `,
-          -1
-        );
-        break;
-      case 6:
-        this.editor.setValue(
-          `package main
-  
-  import (
-      "bufio"
-      "fmt"
-      "os"
-      "strings"
-  )
-  
-  func main() {
-      scanner := bufio.NewScanner(os.Stdin)
-      
-      fmt.Println("What's your name?")
-      scanner.Scan()
-      name := scanner.Text()
-      
-      fmt.Println("What's your favorite color?")
-      scanner.Scan()
-      color := scanner.Text()
-      
-      fmt.Printf("Nice to meet you, %s! %s is a great color!\\n", 
-          strings.TrimSpace(name), 
-          strings.TrimSpace(color))
-  }`,
-          -1
-        );
-        break;
-      case 7:
-        const cols = Math.floor(window.innerWidth / 8);
-        const rows = Math.floor(window.innerHeight / 56);
-
-        this.editor.setValue(
-          `package main
-  import (
-  "bytes"
-  "fmt"
-  "math/rand"
-  "time"
-  )
-  
-  type Field struct {
-  s    [][]bool
-  w, h int
-  }
-  
-  func NewField(w, h int) *Field {
-  s := make([][]bool, h)
-  for i := range s {
-    s[i] = make([]bool, w)
-  }
-  return &Field{s: s, w: w, h: h}
-  }
-  
-  func (f *Field) Set(x, y int, b bool) {
-  f.s[y][x] = b
-  }
-  
-  func (f *Field) Alive(x, y int) bool {
-  x += f.w
-  x %= f.w
-  y += f.h
-  y %= f.h
-  return f.s[y][x]
-  }
-  
-  func (f *Field) Next(x, y int) bool {
-  alive := 0
-  for i := -1; i <= 1; i++ {
-    for j := -1; j <= 1; j++ {
-      if (j != 0 || i != 0) && f.Alive(x+i, y+j) {
-        alive++
-      }
-    }
-  }
-  return alive == 3 || alive == 2 && f.Alive(x, y)
-  }
-  
-  type Life struct {
-  a, b *Field
-  w, h int
-  }
-  
-  func NewLife(w, h int) *Life {
-  a := NewField(w, h)
-  for i := 0; i < (w * h / 4); i++ {
-    a.Set(rand.Intn(w), rand.Intn(h), true)
-  }
-  return &Life{
-    a: a, b: NewField(w, h),
-    w: w, h: h,
-  }
-  }
-  
-  func (l *Life) Step() {
-  for y := 0; y < l.h; y++ {
-    for x := 0; x < l.w; x++ {
-      l.b.Set(x, y, l.a.Next(x, y))
-    }
-  }
-  l.a, l.b = l.b, l.a
-  }
-  
-  func (l *Life) String() string {
-  var buf bytes.Buffer
-  for y := 0; y < l.h; y++ {
-    for x := 0; x < l.w; x++ {
-      b := byte(' ')
-      if l.a.Alive(x, y) {
-        b = 'x'
+    try {
+      const response = await fetch(
+        `/api/examples/${encodeURIComponent(example)}`
+      );
+      if (!response.ok) {
+        throw new Error(await response.text());
       }
-      buf.WriteByte(b)
-    }
-    buf.WriteByte('\\n')
-  }
-  return buf.String()
-  }
-  
-  func main() {
-  l := NewLife(${cols},${rows})
-  for i := 0; i < 75; i++ {
-    l.Step()
-    fmt.Print("", l)
-    time.Sleep(time.Second / 10)
-  }
-  }`,
-          -1
-        );
-        break;
-      case 8:
-        this.editor.setValue(
-          `package main
-  
-  import (
-  "fmt"
-  "strings"
-  "time"
-  )
-  
-  func main() {
-  const col = 30
-  // Clear the screen by printing \x0c.
-  bar := fmt.Sprintf("\x0c[%%-%vs]", col)
-  for i := 0; i < col; i++ {
-    fmt.Printf(bar, strings.Repeat("=", i)+">")
-    time.Sleep(100 * time.Millisecond)
-  }
-  fmt.Printf(bar+" Done!", strings.Repeat("=", col))
-  }
-  `,
-          -1
-        );
-        break;
+
+      const { code } = await response.json();
+      this.editor.setValue(code, -1);
+    } catch (error) {
+      this.handleError(error);
     }
   }
 }
@@ -730,6 +504,9 @@ const editorApp = new Editor();
 function selectMenuItem(option) {
   if (option) {
     editorApp.state.currentExample = option;
+  } else if (!editorApp.state.currentExample) {
+    const first = document.querySelector(".dropdown-content [data-example]");
+    editorApp.state.currentExample = first ? first.dataset.example : "";
   }
 
   editorApp.resetCode(editorApp.state.currentExample);
@@ -4,6 +4,6 @@ import (
 	"embed"
 )
 
-//go:embed form.html
+//go:embed form.html embed.html learn.html lesson.html classroom.html room.html dashboard.html
 
 var Templates embed.FS